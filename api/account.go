@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"simplebank/currency"
+	db "simplebank/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errAccountNotOwned = errors.New("account doesn't belong to the authenticated user")
+
+// accountResponse is an account with its balances also written as decimal amounts of its currency,
+// so that clients do not need to know how many minor units each currency has.
+type accountResponse struct {
+	db.Account
+	Money accountMoney `json:"money"`
+}
+
+type accountMoney struct {
+	Balance          currency.Money `json:"balance"`
+	AvailableBalance currency.Money `json:"available_balance"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account: account,
+		Money: accountMoney{
+			Balance:          currency.New(account.Balance, account.Currency),
+			AvailableBalance: currency.New(account.AvailableBalance, account.Currency),
+		},
+	}
+}
+
+type createAccountRequest struct {
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createAccount(ctx *gin.Context) {
+	var req createAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    authPayload(ctx).Username,
+		Currency: req.Currency,
+		Balance:  0,
+	}
+
+	account, err := server.store.CreateAccountTx(ctx, arg)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+type accountIDRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getAccount(ctx *gin.Context) {
+	var req accountIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, ok := server.ownedAccount(ctx, req.ID)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+type listAccountsRequest struct {
+	PageToken string `form:"page_token" binding:"page_token"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Status    string `form:"status" binding:"omitempty,oneof=active frozen closed"`
+}
+
+// listAccounts returns a page of the accounts of the authenticated user.
+// The next page is read by passing the returned next_page_token as page_token.
+func (server *Server) listAccounts(ctx *gin.Context) {
+	var req listAccountsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	status := db.NullAccountStatus{
+		AccountStatus: db.AccountStatus(req.Status),
+		Valid:         req.Status != "",
+	}
+	page, err := db.PageAccountsByOwner(ctx, server.store, authPayload(ctx).Username, status, db.PageRequest{
+		PageToken: req.PageToken,
+		PageSize:  req.PageSize,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	rsp := db.Page[accountResponse]{
+		Items:         make([]accountResponse, len(page.Items)),
+		NextPageToken: page.NextPageToken,
+	}
+	for i, account := range page.Items {
+		rsp.Items[i] = newAccountResponse(account)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// closeAccount closes an account instead of deleting it, so that its history stays.
+// The balance must be zero and nothing may be pending on the account.
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var req accountIDRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, req.ID); !ok {
+		return
+	}
+
+	account, err := server.store.CloseAccountTx(ctx, req.ID)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
+}
+
+// ownedAccount loads an account and checks that it belongs to the authenticated user.
+// It writes the error response itself and returns false if the request must stop.
+func (server *Server) ownedAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return account, false
+	}
+
+	if account.Owner != authPayload(ctx).Username {
+		ctx.JSON(http.StatusForbidden, errorResponse(errAccountNotOwned))
+		return account, false
+	}
+
+	return account, true
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getEntryRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getEntry(ctx *gin.Context) {
+	var req getEntryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	entry, err := server.store.GetEntry(ctx, req.ID)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, entry.AccountID); !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+type listEntriesRequest struct {
+	PageToken string `form:"page_token" binding:"page_token"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listEntries returns a page of the entries of an account, oldest first.
+func (server *Server) listEntries(ctx *gin.Context) {
+	var uri accountIDRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	page, err := db.PageEntriesByAccount(ctx, server.store, uri.ID, db.PageRequest{
+		PageToken: req.PageToken,
+		PageSize:  req.PageSize,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
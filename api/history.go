@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+type listAccountHistoryRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type auditLogResponse struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor,omitempty"`
+	Action    db.AuditAction  `json:"action"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func newAuditLogResponse(log db.AuditLog) auditLogResponse {
+	return auditLogResponse{
+		ID:        log.ID,
+		Actor:     log.Actor.String,
+		Action:    log.Action,
+		Before:    log.Before,
+		After:     log.After,
+		RequestID: log.RequestID.String,
+		CreatedAt: log.CreatedAt,
+	}
+}
+
+// listAccountHistory returns every change made to an account, oldest first.
+func (server *Server) listAccountHistory(ctx *gin.Context) {
+	var uri accountIDRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAccountHistoryRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	arg := db.ListAuditLogByEntityParams{
+		Entity:   db.AuditEntityAccount,
+		EntityID: strconv.FormatInt(uri.ID, 10),
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
+	}
+
+	logs, err := server.store.ListAuditLogByEntity(ctx, arg)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	rsp := make([]auditLogResponse, len(logs))
+	for i, log := range logs {
+		rsp[i] = newAuditLogResponse(log)
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
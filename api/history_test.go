@@ -0,0 +1,195 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAccountHistoryAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	logs := []db.AuditLog{
+		{
+			ID:        1,
+			Actor:     sql.NullString{String: user.Username, Valid: true},
+			Action:    db.AuditActionCreate,
+			Entity:    db.AuditEntityAccount,
+			EntityID:  strconv.FormatInt(account.ID, 10),
+			Before:    json.RawMessage(`null`),
+			After:     json.RawMessage(`{"balance":0}`),
+			RequestID: sql.NullString{String: "request", Valid: true},
+		},
+		{
+			ID:       2,
+			Action:   db.AuditActionUpdate,
+			Entity:   db.AuditEntityAccount,
+			EntityID: strconv.FormatInt(account.ID, 10),
+			Before:   json.RawMessage(`{"balance":0}`),
+			After:    json.RawMessage(`{"balance":10}`),
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditLogByEntityParams{
+					Entity:   db.AuditEntityAccount,
+					EntityID: strconv.FormatInt(account.ID, 10),
+					Limit:    5,
+					Offset:   0,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAuditLogByEntity(gomock.Any(), gomock.Eq(arg)).Times(1).Return(logs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []auditLogResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Len(t, got, len(logs))
+				for i, log := range logs {
+					require.Equal(t, newAuditLogResponse(log), got[i])
+				}
+			},
+		},
+		{
+			name:  "UnauthorizedUser",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAuditLogByEntity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "NoAuthorization",
+			query: "page_id=1&page_size=5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAuditLogByEntity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			query: "page_id=1&page_size=100",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogByEntity(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/history?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestAccountHistoryRecordsRequest runs against the memory store, to check that the actor and request ID
+// of an HTTP request end up in the audit log.
+func TestAccountHistoryRecordsRequest(t *testing.T) {
+	store := db.NewMemoryStore()
+	user, _ := randomUser(t)
+	_, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: user.HashedPassword,
+		FullName:       user.FullName,
+		Email:          user.Email,
+	})
+	require.NoError(t, err)
+
+	server := newTestServer(t, store)
+
+	body, err := json.Marshal(gin.H{"currency": "USD"})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set(requestIDHeaderKey, "create-account")
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "create-account", recorder.Header().Get(requestIDHeaderKey))
+
+	var account db.Account
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &account))
+
+	url := fmt.Sprintf("/accounts/%d/history?page_id=1&page_size=5", account.ID)
+	request, err = http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotEmpty(t, recorder.Header().Get(requestIDHeaderKey))
+
+	var history []auditLogResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &history))
+	require.Len(t, history, 1)
+	require.Equal(t, db.AuditActionCreate, history[0].Action)
+	require.Equal(t, user.Username, history[0].Actor)
+	require.Equal(t, "create-account", history[0].RequestID)
+	require.JSONEq(t, `null`, string(history[0].Before))
+}
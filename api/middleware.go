@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	db "simplebank/db/sqlc"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+
+	requestIDHeaderKey = "X-Request-ID"
+)
+
+// requestIDMiddleware gives every request an ID, taken from the X-Request-ID header or generated,
+// echoes it in the response and hands it to the store, which records it in the audit log.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Header(requestIDHeaderKey, requestID)
+		ctx.Request = ctx.Request.WithContext(db.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Next()
+	}
+}
+
+// authMiddleware creates a gin middleware for authorization
+func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			err := errors.New("authorization header is not provided")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) < 2 {
+			err := errors.New("invalid authorization header format")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		accessToken := fields[1]
+		payload, err := tokenMaker.VerifyToken(accessToken)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Request = ctx.Request.WithContext(db.WithActor(ctx.Request.Context(), payload.Username))
+		ctx.Next()
+	}
+}
+
+// authPayload returns the token payload stored by authMiddleware
+func authPayload(ctx *gin.Context) *token.Payload {
+	return ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+}
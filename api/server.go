@@ -0,0 +1,115 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Server serves HTTP requests for our banking service.
+type Server struct {
+	config     utils.Config
+	store      db.Store
+	tokenMaker token.Maker
+	router     *gin.Engine
+}
+
+// NewServer creates a new HTTP server and sets up routing.
+func NewServer(config utils.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+	}
+
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("page_token", validPageToken)
+	}
+
+	server.setupRouter()
+	return server, nil
+}
+
+func (server *Server) setupRouter() {
+	router := gin.Default()
+	// let the store see the request context, e.g. the actor and request ID it records in the audit log
+	router.ContextWithFallback = true
+	router.Use(requestIDMiddleware())
+
+	router.POST("/users", server.createUser)
+	router.POST("/users/login", server.loginUser)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+
+	authRoutes.POST("/accounts", server.createAccount)
+	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts", server.listAccounts)
+	authRoutes.DELETE("/accounts/:id", server.closeAccount)
+	authRoutes.GET("/accounts/:id/entries", server.listEntries)
+	authRoutes.GET("/accounts/:id/transfers", server.listTransfers)
+	authRoutes.GET("/accounts/:id/statement", server.getStatement)
+	authRoutes.GET("/accounts/:id/history", server.listAccountHistory)
+
+	authRoutes.GET("/entries/:id", server.getEntry)
+
+	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.GET("/transfers/:id", server.getTransfer)
+
+	server.router = router
+}
+
+// Start runs the HTTP server on a specific address.
+func (server *Server) Start(address string) error {
+	return server.router.Run(address)
+}
+
+func errorResponse(err error) gin.H {
+	return gin.H{"error": err.Error()}
+}
+
+// storeErrorResponse maps an error returned by the store to an HTTP status and body.
+func storeErrorResponse(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	case errors.Is(err, db.ErrCurrencyMismatch), errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrInvalidAmount), errors.Is(err, db.ErrAmountOverflow),
+		errors.Is(err, db.ErrInvalidPeriod), errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed),
+		errors.Is(err, db.ErrAccountNotEmpty), errors.Is(err, db.ErrAccountPendingActivity):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		return
+	case errors.Is(err, db.ErrIdempotencyKeyConflict):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	switch {
+	case errors.Is(err, db.ErrUniqueViolation):
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+	case errors.Is(err, db.ErrForeignKeyViolation):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+	default:
+		switch db.ErrorCode(err) {
+		case db.CheckViolation, db.NotNullViolation:
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+	}
+}
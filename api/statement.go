@@ -0,0 +1,70 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/report"
+
+	"github.com/gin-gonic/gin"
+)
+
+type getStatementRequest struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" binding:"required,gtfield=From" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format string    `form:"format" binding:"omitempty,oneof=json csv text"`
+}
+
+// statementRenderers maps the format query parameter to a renderer and its content type.
+var statementRenderers = map[string]struct {
+	contentType string
+	render      func(w io.Writer, statement db.AccountStatement) error
+}{
+	"json": {"application/json; charset=utf-8", report.WriteJSON},
+	"csv":  {"text/csv; charset=utf-8", report.WriteCSV},
+	"text": {"text/plain; charset=utf-8", report.WriteText},
+}
+
+// getStatement returns the statement of an account for the period [from, to), in JSON unless another format is asked for.
+func (server *Server) getStatement(ctx *gin.Context) {
+	var uri accountIDRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req getStatementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.Format == "" {
+		req.Format = "json"
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	statement, err := server.store.GetAccountStatement(ctx, db.GetAccountStatementParams{
+		AccountID: uri.ID,
+		From:      req.From,
+		To:        req.To,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	renderer := statementRenderers[req.Format]
+	var buf bytes.Buffer
+	if err := renderer.render(&buf, statement); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Data(http.StatusOK, renderer.contentType, buf.Bytes())
+}
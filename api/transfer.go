@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyKeyHeader lets clients retry a transfer safely: requests sharing a key move the money only once.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type transferRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+func (server *Server) createTransfer(ctx *gin.Context) {
+	var req transferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !server.validAccount(ctx, req.FromAccountID, req.Currency) {
+		return
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID:  req.FromAccountID,
+		ToAccountID:    req.ToAccountID,
+		Amount:         db.Amount(req.Amount),
+		IdempotencyKey: ctx.GetHeader(idempotencyKeyHeader),
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// validAccount checks that the account belongs to the authenticated user and holds the requested currency.
+// The store re-checks both accounts inside the transfer transaction; this gives the client a clearer error early.
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) bool {
+	account, ok := server.ownedAccount(ctx, accountID)
+	if !ok {
+		return false
+	}
+
+	if account.Currency != currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return false
+	}
+
+	return true
+}
+
+type getTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getTransfer(ctx *gin.Context) {
+	var req getTransferRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.ID)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	// either side of the transfer may look at it
+	fromAccount, err := server.store.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+	if fromAccount.Owner != authPayload(ctx).Username {
+		if _, ok := server.ownedAccount(ctx, transfer.ToAccountID); !ok {
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+type listTransfersRequest struct {
+	PageToken string `form:"page_token" binding:"page_token"`
+	PageSize  int32  `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listTransfers returns a page of the transfers going out of or coming into an account, oldest first.
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var uri accountIDRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, ok := server.ownedAccount(ctx, uri.ID); !ok {
+		return
+	}
+
+	page, err := db.PageTransfers(ctx, server.store, uri.ID, uri.ID, db.PageRequest{
+		PageToken: req.PageToken,
+		PageSize:  req.PageSize,
+	})
+	if err != nil {
+		storeErrorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
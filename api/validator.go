@@ -0,0 +1,23 @@
+package api
+
+import (
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if currency, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.IsSupportedCurrency(currency)
+	}
+	return false
+}
+
+var validPageToken validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if token, ok := fieldLevel.Field().Interface().(string); ok {
+		_, err := db.ParsePageToken(token)
+		return err == nil
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// Command reconcile checks the ledger invariants and prints the report as JSON.
+// It exits with status 0 when the ledger is consistent, 1 when it found discrepancies
+// and 2 when the check could not run.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/reconcile"
+
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	configPath := flag.String("config", ".", "directory holding app.env")
+	batchSize := flag.Int("batch-size", reconcile.DefaultBatchSize, "number of rows read per query")
+	flag.Parse()
+
+	config, err := utils.LoadConfig(*configPath)
+	if err != nil {
+		fatal("cannot load config:", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		fatal("cannot connect to db:", err)
+	}
+	defer conn.Close()
+
+	report, err := reconcile.Run(context.Background(), db.New(conn), reconcile.Options{BatchSize: int32(*batchSize)})
+	if err != nil {
+		fatal("cannot reconcile ledger:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fatal("cannot write report:", err)
+	}
+
+	if !report.OK() {
+		log.Printf("found %d balance drifts, %d unmatched transfers and %d orphan entries",
+			len(report.BalanceDrifts), len(report.UnmatchedTransfers), len(report.OrphanEntries))
+		os.Exit(1)
+	}
+}
+
+func fatal(v ...any) {
+	log.Println(v...)
+	os.Exit(2)
+}
@@ -0,0 +1,54 @@
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrOverflow is returned when the result of an operation on amounts does not fit in an int64.
+	ErrOverflow = errors.New("amount overflow")
+	// ErrMismatch is returned when an operation mixes amounts of different currencies.
+	ErrMismatch = errors.New("currency mismatch")
+)
+
+// Add returns a + b, failing with ErrOverflow instead of wrapping around.
+func Add(a, b int64) (int64, error) {
+	if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+		return 0, fmt.Errorf("%w: %d + %d", ErrOverflow, a, b)
+	}
+	return a + b, nil
+}
+
+// Sub returns a - b, failing with ErrOverflow instead of wrapping around.
+func Sub(a, b int64) (int64, error) {
+	if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+		return 0, fmt.Errorf("%w: %d - %d", ErrOverflow, a, b)
+	}
+	return a - b, nil
+}
+
+// Add returns the sum of two amounts of the same currency.
+func (money Money) Add(other Money) (Money, error) {
+	if money.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrMismatch, money.Currency, other.Currency)
+	}
+	amount, err := Add(money.Amount, other.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: money.Currency}, nil
+}
+
+// Sub returns the difference of two amounts of the same currency.
+func (money Money) Sub(other Money) (Money, error) {
+	if money.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrMismatch, money.Currency, other.Currency)
+	}
+	amount, err := Sub(money.Amount, other.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: money.Currency}, nil
+}
@@ -0,0 +1,82 @@
+package currency
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdd(t *testing.T) {
+	testCases := []struct {
+		a, b     int64
+		want     int64
+		overflow bool
+	}{
+		{a: 1, b: 2, want: 3},
+		{a: -1, b: -2, want: -3},
+		{a: math.MaxInt64, b: 0, want: math.MaxInt64},
+		{a: math.MaxInt64, b: math.MinInt64, want: -1},
+		{a: math.MaxInt64 - 1, b: 1, want: math.MaxInt64},
+		{a: math.MaxInt64, b: 1, overflow: true},
+		{a: 1, b: math.MaxInt64, overflow: true},
+		{a: math.MinInt64, b: -1, overflow: true},
+		{a: math.MinInt64 + 1, b: -1, want: math.MinInt64},
+	}
+
+	for _, tc := range testCases {
+		got, err := Add(tc.a, tc.b)
+		if tc.overflow {
+			require.ErrorIs(t, err, ErrOverflow, "%d + %d", tc.a, tc.b)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got)
+	}
+}
+
+func TestSub(t *testing.T) {
+	testCases := []struct {
+		a, b     int64
+		want     int64
+		overflow bool
+	}{
+		{a: 3, b: 2, want: 1},
+		{a: 0, b: math.MaxInt64, want: -math.MaxInt64},
+		{a: -1, b: math.MaxInt64, want: math.MinInt64},
+		{a: -2, b: math.MaxInt64, overflow: true},
+		{a: 0, b: math.MinInt64, overflow: true},
+		{a: math.MaxInt64, b: -1, overflow: true},
+		{a: -1, b: math.MinInt64, want: math.MaxInt64},
+	}
+
+	for _, tc := range testCases {
+		got, err := Sub(tc.a, tc.b)
+		if tc.overflow {
+			require.ErrorIs(t, err, ErrOverflow, "%d - %d", tc.a, tc.b)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tc.want, got)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := New(150, "USD").Add(New(250, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(400, "USD"), sum)
+
+	diff, err := New(150, "USD").Sub(New(250, "USD"))
+	require.NoError(t, err)
+	require.Equal(t, New(-100, "USD"), diff)
+
+	_, err = New(1, "USD").Add(New(1, "EUR"))
+	require.ErrorIs(t, err, ErrMismatch)
+	_, err = New(1, "USD").Sub(New(1, "EUR"))
+	require.ErrorIs(t, err, ErrMismatch)
+
+	_, err = New(math.MaxInt64, "JPY").Add(New(1, "JPY"))
+	require.ErrorIs(t, err, ErrOverflow)
+	_, err = New(math.MinInt64, "JPY").Sub(New(1, "JPY"))
+	require.ErrorIs(t, err, ErrOverflow)
+}
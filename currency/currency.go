@@ -0,0 +1,58 @@
+// Package currency lists the ISO 4217 currencies the bank supports
+// and converts amounts between minor units and their decimal form.
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnsupported is returned for a currency code the bank does not support.
+var ErrUnsupported = errors.New("unsupported currency")
+
+// Currency is an ISO 4217 currency.
+type Currency struct {
+	Code string `json:"code"`
+	// Exponent is the number of decimals of the minor unit: 2 for USD cents, 0 for JPY, 3 for BHD fils.
+	Exponent int `json:"exponent"`
+}
+
+// registry holds the supported currencies.
+// The currencies table of the database is seeded with the same list.
+var registry = map[string]Currency{
+	"AUD": {Code: "AUD", Exponent: 2},
+	"BHD": {Code: "BHD", Exponent: 3},
+	"CAD": {Code: "CAD", Exponent: 2},
+	"CHF": {Code: "CHF", Exponent: 2},
+	"EUR": {Code: "EUR", Exponent: 2},
+	"GBP": {Code: "GBP", Exponent: 2},
+	"JPY": {Code: "JPY", Exponent: 0},
+	"KWD": {Code: "KWD", Exponent: 3},
+	"USD": {Code: "USD", Exponent: 2},
+}
+
+// Lookup returns the currency with the given code.
+func Lookup(code string) (Currency, error) {
+	currency, ok := registry[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnsupported, code)
+	}
+	return currency, nil
+}
+
+// IsSupported reports whether code is a supported currency.
+func IsSupported(code string) bool {
+	_, ok := registry[code]
+	return ok
+}
+
+// All returns every supported currency, ordered by code.
+func All() []Currency {
+	currencies := make([]Currency, 0, len(registry))
+	for _, currency := range registry {
+		currencies = append(currencies, currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool { return currencies[i].Code < currencies[j].Code })
+	return currencies
+}
@@ -0,0 +1,30 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup(t *testing.T) {
+	currency, err := Lookup("JPY")
+	require.NoError(t, err)
+	require.Equal(t, Currency{Code: "JPY", Exponent: 0}, currency)
+
+	_, err = Lookup("usd")
+	require.ErrorIs(t, err, ErrUnsupported)
+
+	require.True(t, IsSupported("BHD"))
+	require.False(t, IsSupported("XXX"))
+}
+
+func TestAll(t *testing.T) {
+	all := All()
+	require.Len(t, all, len(registry))
+	for i, currency := range all {
+		require.Len(t, currency.Code, 3)
+		if i > 0 {
+			require.Less(t, all[i-1].Code, currency.Code)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package currency
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAmount is returned when a decimal amount cannot be parsed for its currency.
+// The store returns it too, for amounts of money to move that are not positive.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Money is an amount in the minor units of a currency, like the balances and amounts stored by the bank.
+// It formats to and parses from the decimal form people read, with as many decimals as the currency has.
+type Money struct {
+	// Amount is in minor units: cents for USD, yen for JPY.
+	Amount   int64
+	Currency string
+}
+
+// New returns amount minor units of the currency code.
+func New(amount int64, code string) Money {
+	return Money{Amount: amount, Currency: code}
+}
+
+// Format writes the amount in decimal form, without the currency code: 1234 USD is "12.34", 1234 JPY is "1234".
+// An unsupported currency is written in minor units.
+func (money Money) Format() string {
+	currency, err := Lookup(money.Currency)
+	if err != nil || currency.Exponent == 0 {
+		return strconv.FormatInt(money.Amount, 10)
+	}
+
+	sign := ""
+	// strconv.FormatUint of the magnitude also works for math.MinInt64
+	magnitude := uint64(money.Amount)
+	if money.Amount < 0 {
+		sign = "-"
+		magnitude = -magnitude
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if len(digits) <= currency.Exponent {
+		digits = strings.Repeat("0", currency.Exponent-len(digits)+1) + digits
+	}
+	point := len(digits) - currency.Exponent
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// String writes the amount followed by the currency code, like "12.34 USD".
+func (money Money) String() string {
+	return money.Format() + " " + money.Currency
+}
+
+// Parse reads a decimal amount of the currency code, like "12.34" for USD or "1.005" for BHD.
+// It refuses more decimals than the currency has, so that no amount is silently rounded.
+func Parse(s string, code string) (Money, error) {
+	currency, err := Lookup(code)
+	if err != nil {
+		return Money{}, err
+	}
+
+	digits := s
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if whole == "" || (hasPoint && fraction == "") || len(fraction) > currency.Exponent ||
+		!isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%w: %q for %s", ErrInvalidAmount, s, code)
+	}
+
+	fraction += strings.Repeat("0", currency.Exponent-len(fraction))
+	magnitude, err := strconv.ParseUint(whole+fraction, 10, 64)
+	if err != nil || magnitude > math.MaxInt64 {
+		return Money{}, fmt.Errorf("%w: %q for %s is out of range", ErrInvalidAmount, s, code)
+	}
+
+	amount := int64(magnitude)
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: code}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// moneyJSON is the JSON form of Money. The amount is a string, so that no client reads it as a float.
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func (money Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: money.Format(), Currency: money.Currency})
+}
+
+func (money *Money) UnmarshalJSON(data []byte) error {
+	var m moneyJSON
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+
+	parsed, err := Parse(m.Amount, m.Currency)
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
@@ -0,0 +1,102 @@
+package currency
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		money Money
+		want  string
+	}{
+		{New(1234, "USD"), "12.34"},
+		{New(5, "USD"), "0.05"},
+		{New(0, "EUR"), "0.00"},
+		{New(-1234, "USD"), "-12.34"},
+		{New(-5, "CAD"), "-0.05"},
+		{New(1234, "JPY"), "1234"},
+		{New(-1234, "JPY"), "-1234"},
+		{New(1005, "BHD"), "1.005"},
+		{New(7, "KWD"), "0.007"},
+		{New(math.MaxInt64, "USD"), "92233720368547758.07"},
+		{New(math.MinInt64, "USD"), "-92233720368547758.08"},
+		{New(1234, "XXX"), "1234"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.want, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.money.Format())
+		})
+	}
+
+	require.Equal(t, "12.34 USD", New(1234, "USD").String())
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		s      string
+		code   string
+		amount int64
+	}{
+		{"12.34", "USD", 1234},
+		{"12.3", "USD", 1230},
+		{"12", "USD", 1200},
+		{"0.05", "USD", 5},
+		{"-12.34", "USD", -1234},
+		{"1234", "JPY", 1234},
+		{"1.005", "BHD", 1005},
+		{"92233720368547758.07", "USD", math.MaxInt64},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code+" "+tc.s, func(t *testing.T) {
+			money, err := Parse(tc.s, tc.code)
+			require.NoError(t, err)
+			require.Equal(t, New(tc.amount, tc.code), money)
+
+			// formatting gives back the canonical form, which parses to the same amount
+			again, err := Parse(money.Format(), tc.code)
+			require.NoError(t, err)
+			require.Equal(t, money, again)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct{ s, code string }{
+		{"", "USD"},
+		{"-", "USD"},
+		{".5", "USD"},
+		{"1.", "USD"},
+		{"1.234", "USD"},
+		{"1.5", "JPY"},
+		{"1.0005", "BHD"},
+		{"1,5", "EUR"},
+		{"+1", "USD"},
+		{"1e3", "USD"},
+		{"92233720368547758.08", "USD"},
+		{"99999999999999999999999", "USD"},
+	} {
+		_, err := Parse(tc.s, tc.code)
+		require.ErrorIs(t, err, ErrInvalidAmount, "%s %s", tc.s, tc.code)
+	}
+
+	_, err := Parse("1", "XXX")
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(New(-1005, "BHD"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount": "-1.005", "currency": "BHD"}`, string(data))
+
+	var money Money
+	require.NoError(t, json.Unmarshal(data, &money))
+	require.Equal(t, New(-1005, "BHD"), money)
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"amount": "1.5", "currency": "JPY"}`), &money), ErrInvalidAmount)
+}
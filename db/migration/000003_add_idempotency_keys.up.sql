@@ -0,0 +1,10 @@
+CREATE TABLE "idempotency_keys" (
+  "key" varchar PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "result" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "idempotency_keys"."result" IS 'serialized TransferTxResult returned on replay';
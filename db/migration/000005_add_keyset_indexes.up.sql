@@ -0,0 +1,12 @@
+CREATE INDEX ON "accounts" ("created_at", "id");
+
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
+-- supersedes the (account_id, created_at) index, which is a prefix of this one
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "type";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "transfer_id";
+
+DROP TYPE IF EXISTS "entry_type";
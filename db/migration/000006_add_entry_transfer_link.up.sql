@@ -0,0 +1,28 @@
+CREATE TYPE "entry_type" AS ENUM (
+  'transfer',
+  'deposit',
+  'withdrawal',
+  'fee',
+  'adjustment'
+);
+
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD COLUMN "type" entry_type NOT NULL DEFAULT 'adjustment';
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer this entry is a leg of';
+
+-- Link the entries written by TransferTx so far: both legs carry the transaction timestamp as created_at.
+UPDATE "entries" e
+SET "transfer_id" = t."id", "type" = 'transfer'
+FROM "transfers" t
+WHERE t."created_at" = e."created_at"
+  AND ((t."from_account_id" = e."account_id" AND t."amount" = -e."amount")
+    OR (t."to_account_id" = e."account_id" AND t."amount" = e."amount"));
+
+-- Every new entry states its type.
+ALTER TABLE "entries" ALTER COLUMN "type" DROP DEFAULT;
@@ -0,0 +1,3 @@
+ALTER TABLE "entries" ADD COLUMN "external_ref" varchar;
+
+COMMENT ON COLUMN "entries"."external_ref" IS 'reference of the deposit or withdrawal in the external system';
@@ -0,0 +1,18 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reason" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_reversed_amount_check"
+  CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "amount");
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer this transfer reverses';
+
+COMMENT ON COLUMN "transfers"."reason" IS 'why the transfer was reversed';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'part of the amount already sent back by reversals';
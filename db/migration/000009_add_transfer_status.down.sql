@@ -0,0 +1,9 @@
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "held_balance";
+
+COMMENT ON COLUMN "accounts"."balance" IS NULL;
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "status";
+
+DROP TYPE IF EXISTS "transfer_status";
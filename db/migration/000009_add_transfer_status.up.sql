@@ -0,0 +1,24 @@
+CREATE TYPE "transfer_status" AS ENUM (
+  'pending',
+  'posted',
+  'failed'
+);
+
+-- transfers written so far are final
+ALTER TABLE "transfers" ADD COLUMN "status" transfer_status NOT NULL DEFAULT 'posted';
+
+ALTER TABLE "transfers" ALTER COLUMN "status" DROP DEFAULT;
+
+ALTER TABLE "accounts" ADD COLUMN "held_balance" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint NOT NULL GENERATED ALWAYS AS ("balance" - "held_balance") STORED;
+
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_held_balance_check" CHECK ("held_balance" >= 0);
+
+CREATE INDEX ON "transfers" ("status") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "accounts"."balance" IS 'ledger balance: the sum of the posted entries';
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'part of the balance reserved by pending transfers';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'part of the balance that can be spent';
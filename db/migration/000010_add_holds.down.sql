@@ -0,0 +1,5 @@
+COMMENT ON COLUMN "accounts"."held_balance" IS 'part of the balance reserved by pending transfers';
+
+DROP TABLE IF EXISTS "holds";
+
+DROP TYPE IF EXISTS "hold_status";
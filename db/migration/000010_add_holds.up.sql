@@ -0,0 +1,37 @@
+CREATE TYPE "hold_status" AS ENUM (
+  'active',
+  'released',
+  'captured',
+  'expired'
+);
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "status" hold_status NOT NULL DEFAULT 'active',
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "transfer_id" bigint
+);
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds" ADD CONSTRAINT "holds_amount_check" CHECK ("amount" > 0);
+
+ALTER TABLE "holds" ADD CONSTRAINT "holds_captured_amount_check" CHECK ("captured_amount" BETWEEN 0 AND "amount");
+
+CREATE INDEX ON "holds" ("account_id") WHERE "status" = 'active';
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'active';
+
+CREATE INDEX ON "holds" ("transfer_id");
+
+COMMENT ON COLUMN "holds"."amount" IS 'amount reserved on the account while the hold is active';
+
+COMMENT ON COLUMN "holds"."transfer_id" IS 'transfer that captured the hold';
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'part of the balance reserved by pending transfers and active holds';
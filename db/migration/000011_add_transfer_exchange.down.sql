@@ -0,0 +1,5 @@
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "exchange_rate";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_amount";
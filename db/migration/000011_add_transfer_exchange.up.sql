@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_exchange_check" CHECK (
+  ("to_amount" IS NULL AND "exchange_rate" IS NULL) OR ("to_amount" > 0 AND "exchange_rate" > 0)
+);
+
+COMMENT ON COLUMN "transfers"."amount" IS 'must be positive, in the currency of the source account';
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in the currency of the destination account, when it differs from the source currency';
+
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'units of the destination currency bought by one unit of the source currency';
@@ -0,0 +1,23 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "exponent" smallint NOT NULL
+);
+
+ALTER TABLE "currencies" ADD CONSTRAINT "currencies_exponent_check" CHECK ("exponent" BETWEEN 0 AND 4);
+
+COMMENT ON TABLE "currencies" IS 'supported ISO 4217 currencies, kept in sync with the currency package';
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of decimals of the minor unit';
+
+INSERT INTO "currencies" ("code", "exponent") VALUES
+  ('AUD', 2),
+  ('BHD', 3),
+  ('CAD', 2),
+  ('CHF', 2),
+  ('EUR', 2),
+  ('GBP', 2),
+  ('JPY', 0),
+  ('KWD', 3),
+  ('USD', 2);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "transfers_amount_check";
@@ -0,0 +1,30 @@
+CREATE TYPE "event_type" AS ENUM (
+  'account_created',
+  'transfer_completed',
+  'entry_posted'
+);
+
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" event_type NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "attempts" int NOT NULL DEFAULT 0,
+  "last_error" varchar,
+  "delivered_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "delivered_at" IS NULL;
+
+CREATE INDEX ON "outbox" ("event_type", "aggregate_id");
+
+COMMENT ON TABLE "outbox" IS 'ledger events written in the transaction that caused them, waiting to be published';
+
+COMMENT ON COLUMN "outbox"."aggregate_id" IS 'id of the account, transfer or entry the event is about';
+
+COMMENT ON COLUMN "outbox"."payload" IS 'the account, transfer or entry as JSON';
+
+COMMENT ON COLUMN "outbox"."attempts" IS 'number of times the event was handed to the publisher';
+
+COMMENT ON COLUMN "outbox"."last_error" IS 'why the last delivery failed';
@@ -0,0 +1,17 @@
+-- Postgres delivers the notification when the transaction commits, and drops it on rollback.
+CREATE FUNCTION "notify_account_balance"() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('account_balance', json_build_object(
+    'account_id', NEW."id",
+    'balance', NEW."balance",
+    'available_balance', NEW."available_balance"
+  )::text);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "accounts_notify_balance"
+AFTER UPDATE OF "balance", "held_balance" ON "accounts"
+FOR EACH ROW
+WHEN (OLD."balance" IS DISTINCT FROM NEW."balance" OR OLD."held_balance" IS DISTINCT FROM NEW."held_balance")
+EXECUTE FUNCTION "notify_account_balance"();
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "audit_log";
+
+DROP FUNCTION IF EXISTS "audit_log_append_only"();
+
+DROP TYPE IF EXISTS "audit_action";
@@ -0,0 +1,47 @@
+CREATE TYPE "audit_action" AS ENUM (
+  'create',
+  'update',
+  'delete'
+);
+
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar,
+  "action" audit_action NOT NULL,
+  "entity" varchar NOT NULL,
+  "entity_id" varchar NOT NULL,
+  "before" jsonb NOT NULL,
+  "after" jsonb NOT NULL,
+  "request_id" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_log" ("entity", "entity_id", "id");
+
+COMMENT ON TABLE "audit_log" IS 'append-only record of every change made through the store';
+
+COMMENT ON COLUMN "audit_log"."actor" IS 'user who asked for the change, if known';
+
+COMMENT ON COLUMN "audit_log"."entity" IS 'kind of row changed: account, entry, hold, transfer or user';
+
+COMMENT ON COLUMN "audit_log"."before" IS 'the row before the change, null for a creation';
+
+COMMENT ON COLUMN "audit_log"."after" IS 'the row after the change, null for a deletion';
+
+COMMENT ON COLUMN "audit_log"."request_id" IS 'request the change was made for, to correlate with the logs';
+
+CREATE FUNCTION "audit_log_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only' USING ERRCODE = 'insufficient_privilege';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_no_update"
+BEFORE UPDATE OR DELETE ON "audit_log"
+FOR EACH ROW
+EXECUTE FUNCTION "audit_log_append_only"();
+
+CREATE TRIGGER "audit_log_no_truncate"
+BEFORE TRUNCATE ON "audit_log"
+FOR EACH STATEMENT
+EXECUTE FUNCTION "audit_log_append_only"();
@@ -0,0 +1,18 @@
+-- Going back loses the status of every account: frozen and closed accounts become plain accounts again.
+-- One account per owner and currency cannot be restored while an owner has a closed account next to another one
+-- in the same currency. Both keep their history, so neither can be dropped here: stop and let them be merged by hand.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM "accounts" GROUP BY "owner", "currency" HAVING count(*) > 1) THEN
+    RAISE EXCEPTION 'cannot restore owner_currency_key: some owners have several accounts in the same currency';
+  END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS "owner_currency_key";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "status";
+
+DROP TYPE IF EXISTS "account_status";
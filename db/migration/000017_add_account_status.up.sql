@@ -0,0 +1,14 @@
+CREATE TYPE "account_status" AS ENUM (
+  'active',
+  'frozen',
+  'closed'
+);
+
+ALTER TABLE "accounts" ADD COLUMN "status" account_status NOT NULL DEFAULT 'active';
+
+-- a closed account keeps its history, and no longer stops its owner from opening another one in the same currency
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner", "currency") WHERE "status" <> 'closed';
+
+COMMENT ON COLUMN "accounts"."status" IS 'frozen and closed accounts cannot move money; closed is final';
@@ -0,0 +1,10 @@
+-- Keys are unique per source account only, so the same key may have been used from several accounts.
+-- Going back to globally unique keys keeps the oldest of them and drops the rest.
+DELETE FROM "idempotency_keys" AS k
+USING "idempotency_keys" AS older
+WHERE k."key" = older."key"
+  AND (k."created_at", k."from_account_id") > (older."created_at", older."from_account_id");
+
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT "idempotency_keys_pkey";
+
+ALTER TABLE "idempotency_keys" ADD CONSTRAINT "idempotency_keys_pkey" PRIMARY KEY ("key");
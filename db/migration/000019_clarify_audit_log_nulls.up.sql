@@ -0,0 +1,3 @@
+COMMENT ON COLUMN "audit_log"."before" IS 'the row before the change, the JSON value null for a creation';
+
+COMMENT ON COLUMN "audit_log"."after" IS 'the row after the change, the JSON value null for a deletion';
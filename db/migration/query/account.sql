@@ -10,6 +10,12 @@ RETURNING *;
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: GetAccountForUpdate :one
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
@@ -17,8 +23,9 @@ FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
 SELECT * FROM accounts
+WHERE (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
 ORDER BY id
-LIMIT $1 OFFSET $2;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: UpdateAccount :one
 UPDATE accounts
@@ -36,4 +43,32 @@ RETURNING *;
 UPDATE accounts
 SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ListAccountsByOwner :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
+ORDER BY id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListAccountsPage :many
+SELECT * FROM accounts
+WHERE (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListAccountsByOwnerPage :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(status)::account_status IS NULL OR status = sqlc.narg(status))
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
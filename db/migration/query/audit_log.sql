@@ -0,0 +1,19 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+  actor,
+  action,
+  entity,
+  entity_id,
+  before,
+  after,
+  request_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListAuditLogByEntity :many
+SELECT * FROM audit_log
+WHERE entity = $1 AND entity_id = $2
+ORDER BY id
+LIMIT $3
+OFFSET $4;
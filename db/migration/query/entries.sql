@@ -1,6 +1,6 @@
 -- name: CreateEntry :one
-INSERT INTO entries (account_id, amount) 
-VALUES ($1, $2)
+INSERT INTO entries (account_id, amount, type, transfer_id, external_ref)
+VALUES ($1, $2, $3, $4, $5)
 RETURNING *;
 
 -- name: GetEntry :one
@@ -10,4 +10,31 @@ SELECT * FROM entries WHERE id = $1 LIMIT 1;
 SELECT * FROM entries 
 WHERE account_id = $1
 ORDER BY id
-LIMIT $2 OFFSET $3;
\ No newline at end of file
+LIMIT $2 OFFSET $3;
+
+-- name: GetEntriesTotalSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = sqlc.arg(account_id) AND created_at >= sqlc.arg(since);
+
+-- name: ListStatementEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.type, e.external_ref,
+  t.from_account_id, t.to_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+  AND e.created_at >= sqlc.arg(from_time)
+  AND e.created_at < sqlc.arg(to_time)
+ORDER BY e.created_at, e.id;
+
+-- name: ListEntriesByAccountPage :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListEntriesByTransfer :many
+-- Returns the legs of a transfer: the debit of the source account, then the credit of the destination account.
+SELECT * FROM entries
+WHERE transfer_id = sqlc.arg(transfer_id)::bigint
+ORDER BY amount;
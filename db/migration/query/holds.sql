@@ -0,0 +1,39 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  account_id,
+  amount,
+  expires_at
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListActiveHoldsByAccount :many
+SELECT * FROM holds
+WHERE account_id = $1 AND status = 'active'
+ORDER BY id;
+
+-- name: ListExpiredHoldsForUpdate :many
+-- Locks a batch of active holds that expired at or before expires_before,
+-- skipping the ones another transaction is settling.
+SELECT * FROM holds
+WHERE status = 'active' AND expires_at <= sqlc.arg(expires_before)
+ORDER BY id
+LIMIT sqlc.arg(batch_size)
+FOR NO KEY UPDATE SKIP LOCKED;
+
+-- name: UpdateHold :one
+UPDATE holds
+SET status = sqlc.arg(status),
+  captured_amount = sqlc.arg(captured_amount),
+  transfer_id = sqlc.arg(transfer_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
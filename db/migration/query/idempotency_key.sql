@@ -0,0 +1,15 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  key,
+  from_account_id,
+  to_account_id,
+  amount,
+  result
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE from_account_id = $1 AND key = $2 LIMIT 1;
@@ -0,0 +1,39 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+  event_type,
+  aggregate_id,
+  payload
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox
+WHERE id = $1 LIMIT 1;
+
+-- name: ListOutboxEventsByAggregate :many
+SELECT * FROM outbox
+WHERE event_type = $1 AND aggregate_id = $2
+ORDER BY id;
+
+-- name: ListUndeliveredOutboxEventsForUpdate :many
+-- Locks the oldest undelivered events, skipping the ones another relay is delivering.
+SELECT * FROM outbox
+WHERE delivered_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxEventDelivered :one
+UPDATE outbox
+SET delivered_at = now(),
+  attempts = attempts + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkOutboxEventFailed :one
+UPDATE outbox
+SET attempts = attempts + 1,
+  last_error = $2
+WHERE id = $1
+RETURNING *;
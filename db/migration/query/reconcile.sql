@@ -0,0 +1,33 @@
+-- name: ListAccountEntryTotals :many
+-- Returns a batch of accounts with the sum of their entries, which should equal the balance.
+SELECT a.id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id > sqlc.arg(after_id)
+GROUP BY a.id
+ORDER BY a.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ListTransferEntryMatches :many
+-- Returns a batch of transfers with the number of debit and credit entries linked to each of them.
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.status,
+  (SELECT count(*) FROM entries e
+    WHERE e.transfer_id = t.id AND e.account_id = t.from_account_id AND e.amount = -t.amount) AS debit_entries,
+  (SELECT count(*) FROM entries e
+    WHERE e.transfer_id = t.id AND e.account_id = t.to_account_id AND e.amount = COALESCE(t.to_amount, t.amount)) AS credit_entries
+FROM transfers t
+WHERE t.id > sqlc.arg(after_id)
+ORDER BY t.id
+LIMIT sqlc.arg(batch_size);
+
+-- name: ListEntryTransferMatches :many
+-- Returns a batch of entries with the number of transfers each of them is a leg of, which is 0 or 1.
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.type,
+  (SELECT count(*) FROM transfers t
+    WHERE t.id = e.transfer_id
+      AND ((t.from_account_id = e.account_id AND t.amount = -e.amount)
+        OR (t.to_account_id = e.account_id AND COALESCE(t.to_amount, t.amount) = e.amount))) AS matching_transfers
+FROM entries e
+WHERE e.id > sqlc.arg(after_id)
+ORDER BY e.id
+LIMIT sqlc.arg(batch_size);
@@ -1,11 +1,16 @@
 -- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount, created_at) 
-VALUES ($1, $2, $3, NOW())
+INSERT INTO transfers (from_account_id, to_account_id, amount, created_at, reversal_of, reason, status, to_amount, exchange_rate)
+VALUES ($1, $2, $3, NOW(), $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers WHERE id = $1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfersFromAccount :many
 SELECT * FROM transfers 
 WHERE from_account_id = $1
@@ -25,4 +30,47 @@ WHERE
     to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: ListTransfersFromAccountPage :many
+SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListTransfersToAccountPage :many
+SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(to_account_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: ListTransfersPage :many
+SELECT * FROM transfers
+WHERE (from_account_id = sqlc.arg(from_account_id) OR to_account_id = sqlc.arg(to_account_id))
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_size);
+
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfers
+WHERE reversal_of = sqlc.arg(transfer_id)::bigint
+ORDER BY id;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = sqlc.arg(status)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CountPendingTransfersByAccount :one
+SELECT count(*) FROM transfers
+WHERE status = 'pending'
+  AND (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id));
@@ -7,13 +7,14 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status
 `
 
 type AddAccountBalanceParams struct {
@@ -30,6 +31,37 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+	)
+	return i, err
+}
+
+const addAccountHeldBalance = `-- name: AddAccountHeldBalance :one
+UPDATE accounts
+SET held_balance = held_balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status
+`
+
+type AddAccountHeldBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
@@ -40,7 +72,7 @@ INSERT INTO accounts (
 ) VALUES (
   $1, $2, $3
 )
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status
 `
 
 type CreateAccountParams struct {
@@ -58,6 +90,9 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
@@ -65,7 +100,7 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 const deleteAccount = `-- name: DeleteAccount :one
 DELETE FROM accounts
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status
 `
 
 func (q *Queries) DeleteAccount(ctx context.Context, id int64) (Account, error) {
@@ -77,12 +112,15 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) (Account, error)
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -95,12 +133,15 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -114,23 +155,28 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status FROM accounts
+WHERE ($1::account_status IS NULL OR status = $1)
 ORDER BY id
-LIMIT $1 OFFSET $2
+LIMIT $2 OFFSET $3
 `
 
 type ListAccountsParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	Status NullAccountStatus `json:"status"`
+	Limit  int32             `json:"limit"`
+	Offset int32             `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Status, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +190,168 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.AvailableBalance,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByOwner = `-- name: ListAccountsByOwner :many
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status FROM accounts
+WHERE owner = $1
+  AND ($2::account_status IS NULL OR status = $2)
+ORDER BY id
+LIMIT $3 OFFSET $4
+`
+
+type ListAccountsByOwnerParams struct {
+	Owner  string            `json:"owner"`
+	Status NullAccountStatus `json:"status"`
+	Limit  int32             `json:"limit"`
+	Offset int32             `json:"offset"`
+}
+
+func (q *Queries) ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByOwner,
+		arg.Owner,
+		arg.Status,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.AvailableBalance,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByOwnerPage = `-- name: ListAccountsByOwnerPage :many
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status FROM accounts
+WHERE owner = $1
+  AND ($2::account_status IS NULL OR status = $2)
+  AND (created_at, id) > ($3::timestamptz, $4::bigint)
+ORDER BY created_at, id
+LIMIT $5
+`
+
+type ListAccountsByOwnerPageParams struct {
+	Owner          string            `json:"owner"`
+	Status         NullAccountStatus `json:"status"`
+	AfterCreatedAt time.Time         `json:"after_created_at"`
+	AfterID        int64             `json:"after_id"`
+	PageSize       int32             `json:"page_size"`
+}
+
+func (q *Queries) ListAccountsByOwnerPage(ctx context.Context, arg ListAccountsByOwnerPageParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByOwnerPage,
+		arg.Owner,
+		arg.Status,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.AvailableBalance,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsPage = `-- name: ListAccountsPage :many
+SELECT id, owner, balance, currency, created_at, held_balance, available_balance, status FROM accounts
+WHERE ($1::account_status IS NULL OR status = $1)
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListAccountsPageParams struct {
+	Status         NullAccountStatus `json:"status"`
+	AfterCreatedAt time.Time         `json:"after_created_at"`
+	AfterID        int64             `json:"after_id"`
+	PageSize       int32             `json:"page_size"`
+}
+
+func (q *Queries) ListAccountsPage(ctx context.Context, arg ListAccountsPageParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsPage,
+		arg.Status,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.HeldBalance,
+			&i.AvailableBalance,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -162,7 +370,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
   SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status
 `
 
 type UpdateAccountParams struct {
@@ -179,6 +387,37 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, held_balance, available_balance, status
+`
+
+type UpdateAccountStatusParams struct {
+	Status AccountStatus `json:"status"`
+	ID     int64         `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.Status, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.HeldBalance,
+		&i.AvailableBalance,
+		&i.Status,
 	)
 	return i, err
 }
@@ -15,7 +15,7 @@ func TestQueries_CreateAccount(t *testing.T) {
 
 	// Prepare test data
 	arg := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -44,7 +44,7 @@ func TestQueries_CreateAccount(t *testing.T) {
 func TestQueries_GetAccount(t *testing.T) {
 	// Prepare test data
 	arg := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -69,7 +69,7 @@ func TestQueries_GetAccount(t *testing.T) {
 func TestQueries_DeleteAccount(t *testing.T) {
     // Prepare test data
     arg := CreateAccountParams{
-        Owner:    utils.RandomOwner(),
+        Owner:    createRandomUser(t).Username,
         Balance:  utils.RandomMoney(),
         Currency: utils.RandomCurrency(),
     }
@@ -105,7 +105,7 @@ func TestQueries_DeleteAccount(t *testing.T) {
 func TestQueries_UpdateAccount(t *testing.T) {
     // Create a test account
     createArg := CreateAccountParams{
-        Owner:    utils.RandomOwner(),
+        Owner:    createRandomUser(t).Username,
         Balance:  utils.RandomMoney(),
         Currency: utils.RandomCurrency(),
     }
@@ -149,7 +149,7 @@ func TestQueries_ListAccounts(t *testing.T) {
 	limit := int(arg.Limit)
 	for i := 0; i < limit; i++ {
 		createArg := CreateAccountParams{
-			Owner:    utils.RandomOwner(),
+			Owner:    createRandomUser(t).Username,
 			Balance:  utils.RandomMoney(),
 			Currency: utils.RandomCurrency(),
 		}
@@ -202,7 +202,7 @@ func TestQueries_ListAccounts_Pagination(t *testing.T) {
 func TestQueries_AddAccountBalance(t *testing.T) {
 	// Prepare test data
 	arg := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -249,7 +249,7 @@ func TestQueries_DeleteAccount_NonExisting(t *testing.T) {
 
 func createRandomAccount(t *testing.T) Account {
 	arg := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"fmt"
+
+	"simplebank/currency"
+)
+
+// Amount is the money moved by a transfer, deposit, withdrawal or hold, in the minor units of the currency
+// of its account. Only positive amounts are valid: every transaction of the store calls Validate on the
+// amounts of its parameters before it opens a database transaction.
+type Amount int64
+
+// NewAmount returns value as an Amount, failing with ErrInvalidAmount unless it is positive.
+func NewAmount(value int64) (Amount, error) {
+	amount := Amount(value)
+	return amount, amount.Validate()
+}
+
+// Validate fails with ErrInvalidAmount unless the amount is positive.
+func (amount Amount) Validate() error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: %d is not positive", ErrInvalidAmount, amount)
+	}
+	return nil
+}
+
+// validateOptional is Validate for the parameters where zero stands for a whole amount found in the database.
+func (amount Amount) validateOptional() error {
+	if amount == 0 {
+		return nil
+	}
+	return amount.Validate()
+}
+
+// checkBalanceChange makes sure that change can be added to the balance of account without overflowing a bigint.
+func checkBalanceChange(account Account, change int64) error {
+	if _, err := currency.Add(account.Balance, change); err != nil {
+		return fmt.Errorf("account %d: %w", account.ID, err)
+	}
+	return nil
+}
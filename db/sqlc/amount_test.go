@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"math"
+	"simplebank/db/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferInvalidAmount(t *testing.T) {
+	forEachStore(t, testTransferInvalidAmount)
+}
+
+func testTransferInvalidAmount(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 100)
+
+	// a negative amount must not move money the other way
+	for _, amount := range []Amount{0, -10, math.MinInt64} {
+		_, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount})
+		require.ErrorIs(t, err, ErrInvalidAmount, "amount %d", amount)
+
+		_, err = store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount})
+		require.ErrorIs(t, err, ErrInvalidAmount, "amount %d", amount)
+	}
+	requireAvailableBalance(t, store, account1.ID, 100, 100)
+	requireAvailableBalance(t, store, account2.ID, 100, 100)
+
+	// the database refuses such transfers too
+	_, err := store.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        -10,
+		Status:        TransferStatusPosted,
+	})
+	require.Equal(t, CheckViolation, ErrorCode(err))
+}
+
+func TestNewAmount(t *testing.T) {
+	amount, err := NewAmount(10)
+	require.NoError(t, err)
+	require.Equal(t, Amount(10), amount)
+
+	for _, value := range []int64{0, -10, math.MinInt64} {
+		_, err := NewAmount(value)
+		require.ErrorIs(t, err, ErrInvalidAmount, "amount %d", value)
+	}
+
+	// zero stands for the whole amount where the parameter is optional, but negative amounts never pass
+	require.NoError(t, Amount(0).validateOptional())
+	require.ErrorIs(t, Amount(-1).validateOptional(), ErrInvalidAmount)
+}
+
+func TestAmountOverflow(t *testing.T) {
+	forEachStore(t, testAmountOverflow)
+}
+
+func testAmountOverflow(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, math.MaxInt64-50)
+
+	_, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 51})
+	require.ErrorIs(t, err, ErrAmountOverflow)
+	_, err = store.DepositTx(ctx, DepositTxParams{AccountID: account2.ID, Amount: 51})
+	require.ErrorIs(t, err, ErrAmountOverflow)
+	requireBalance(t, store, account1.ID, 100)
+	requireBalance(t, store, account2.ID, math.MaxInt64-50)
+
+	// up to the largest balance is fine
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 50})
+	require.NoError(t, err)
+	requireBalance(t, store, account2.ID, math.MaxInt64)
+
+	// the database refuses to wrap around
+	_, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{ID: account2.ID, Amount: 1})
+	require.Equal(t, NumericValueOutOfRange, ErrorCode(err))
+	requireBalance(t, store, account2.ID, math.MaxInt64)
+}
@@ -0,0 +1,327 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+)
+
+// Entities recorded in the audit log.
+const (
+	AuditEntityAccount  = "account"
+	AuditEntityEntry    = "entry"
+	AuditEntityHold     = "hold"
+	AuditEntityTransfer = "transfer"
+	AuditEntityUser     = "user"
+)
+
+type auditActorKey struct{}
+
+type auditRequestIDKey struct{}
+
+// WithActor returns a copy of ctx in which the store records actor as the author of its changes.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// WithRequestID returns a copy of ctx in which the store records requestID with its changes.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, auditRequestIDKey{}, requestID)
+}
+
+func contextString(ctx context.Context, key any) sql.NullString {
+	s, _ := ctx.Value(key).(string)
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// auditTx makes every transaction of execTx record its changes in the audit log.
+func auditTx(execTx func(ctx context.Context, opts *sql.TxOptions, fn func(Querier) error) error) func(ctx context.Context, opts *sql.TxOptions, fn func(Querier) error) error {
+	return func(ctx context.Context, opts *sql.TxOptions, fn func(Querier) error) error {
+		return execTx(ctx, opts, func(q Querier) error {
+			return fn(auditQuerier{q})
+		})
+	}
+}
+
+// inTx runs a single write in a transaction of store, so that it is audited.
+func inTx[T any](ctx context.Context, store txStore, write func(q Querier) (T, error)) (T, error) {
+	var result T
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		var err error
+		result, err = write(q)
+		return err
+	})
+	return result, err
+}
+
+// auditQuerier writes an audit log entry next to every change of an account, entry, hold, transfer or user,
+// in the same transaction. The other queries go straight to the wrapped Querier.
+type auditQuerier struct {
+	Querier
+}
+
+// audit records a change of an entity. before is nil for a creation and after is nil for a deletion;
+// the columns are NOT NULL, so they hold the JSON value null instead.
+func (q auditQuerier) audit(ctx context.Context, action AuditAction, entity string, entityID string, before, after any) error {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Querier.CreateAuditLog(ctx, CreateAuditLogParams{
+		Actor:     contextString(ctx, auditActorKey{}),
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+		Before:    beforeJSON,
+		After:     afterJSON,
+		RequestID: contextString(ctx, auditRequestIDKey{}),
+	})
+	return err
+}
+
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+func (q auditQuerier) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	after, err := q.Querier.AddAccountBalance(ctx, arg)
+	if err != nil {
+		return after, err
+	}
+	before := after
+	before.Balance -= arg.Amount
+	before.AvailableBalance -= arg.Amount
+	return after, q.audit(ctx, AuditActionUpdate, AuditEntityAccount, auditID(after.ID), before, after)
+}
+
+func (q auditQuerier) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	after, err := q.Querier.AddAccountHeldBalance(ctx, arg)
+	if err != nil {
+		return after, err
+	}
+	before := after
+	before.HeldBalance -= arg.Amount
+	before.AvailableBalance += arg.Amount
+	return after, q.audit(ctx, AuditActionUpdate, AuditEntityAccount, auditID(after.ID), before, after)
+}
+
+func (q auditQuerier) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	after, err := q.Querier.AddTransferReversedAmount(ctx, arg)
+	if err != nil {
+		return after, err
+	}
+	before := after
+	before.ReversedAmount -= arg.Amount
+	return after, q.audit(ctx, AuditActionUpdate, AuditEntityTransfer, auditID(after.ID), before, after)
+}
+
+func (q auditQuerier) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	account, err := q.Querier.CreateAccount(ctx, arg)
+	if err != nil {
+		return account, err
+	}
+	return account, q.audit(ctx, AuditActionCreate, AuditEntityAccount, auditID(account.ID), nil, account)
+}
+
+func (q auditQuerier) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	entry, err := q.Querier.CreateEntry(ctx, arg)
+	if err != nil {
+		return entry, err
+	}
+	return entry, q.audit(ctx, AuditActionCreate, AuditEntityEntry, auditID(entry.ID), nil, entry)
+}
+
+func (q auditQuerier) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	hold, err := q.Querier.CreateHold(ctx, arg)
+	if err != nil {
+		return hold, err
+	}
+	return hold, q.audit(ctx, AuditActionCreate, AuditEntityHold, auditID(hold.ID), nil, hold)
+}
+
+func (q auditQuerier) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	transfer, err := q.Querier.CreateTransfer(ctx, arg)
+	if err != nil {
+		return transfer, err
+	}
+	return transfer, q.audit(ctx, AuditActionCreate, AuditEntityTransfer, auditID(transfer.ID), nil, transfer)
+}
+
+func (q auditQuerier) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	user, err := q.Querier.CreateUser(ctx, arg)
+	if err != nil {
+		return user, err
+	}
+	// the password hash has no business in the audit log
+	logged := user
+	logged.HashedPassword = ""
+	return user, q.audit(ctx, AuditActionCreate, AuditEntityUser, user.Username, nil, logged)
+}
+
+func (q auditQuerier) DeleteAccount(ctx context.Context, id int64) (Account, error) {
+	account, err := q.Querier.DeleteAccount(ctx, id)
+	if err != nil {
+		return account, err
+	}
+	return account, q.audit(ctx, AuditActionDelete, AuditEntityAccount, auditID(account.ID), account, nil)
+}
+
+func (q auditQuerier) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	before, err := q.Querier.GetAccountForUpdate(ctx, arg.ID)
+	if err != nil {
+		return Account{}, err
+	}
+	after, err := q.Querier.UpdateAccount(ctx, arg)
+	if err != nil {
+		return after, err
+	}
+	return after, q.audit(ctx, AuditActionUpdate, AuditEntityAccount, auditID(after.ID), before, after)
+}
+
+func (q auditQuerier) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	before, err := q.Querier.GetAccountForUpdate(ctx, arg.ID)
+	if err != nil {
+		return Account{}, err
+	}
+	after, err := q.Querier.UpdateAccountStatus(ctx, arg)
+	if err != nil {
+		return after, err
+	}
+	return after, q.audit(ctx, AuditActionUpdate, AuditEntityAccount, auditID(after.ID), before, after)
+}
+
+func (q auditQuerier) UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error) {
+	before, err := q.Querier.GetHoldForUpdate(ctx, arg.ID)
+	if err != nil {
+		return Hold{}, err
+	}
+	after, err := q.Querier.UpdateHold(ctx, arg)
+	if err != nil {
+		return after, err
+	}
+	return after, q.audit(ctx, AuditActionUpdate, AuditEntityHold, auditID(after.ID), before, after)
+}
+
+func (q auditQuerier) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	before, err := q.Querier.GetTransferForUpdate(ctx, arg.ID)
+	if err != nil {
+		return Transfer{}, err
+	}
+	after, err := q.Querier.UpdateTransferStatus(ctx, arg)
+	if err != nil {
+		return after, err
+	}
+	return after, q.audit(ctx, AuditActionUpdate, AuditEntityTransfer, auditID(after.ID), before, after)
+}
+
+// Outside of a transaction, the SQL and memory stores run every audited write in one of their own.
+
+func (store *SQLStore) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.AddAccountBalance(ctx, arg) })
+}
+
+func (store *SQLStore) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.AddAccountHeldBalance(ctx, arg) })
+}
+
+func (store *SQLStore) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Transfer, error) { return q.AddTransferReversedAmount(ctx, arg) })
+}
+
+func (store *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.CreateAccount(ctx, arg) })
+}
+
+func (store *SQLStore) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Entry, error) { return q.CreateEntry(ctx, arg) })
+}
+
+func (store *SQLStore) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Hold, error) { return q.CreateHold(ctx, arg) })
+}
+
+func (store *SQLStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Transfer, error) { return q.CreateTransfer(ctx, arg) })
+}
+
+func (store *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (User, error) { return q.CreateUser(ctx, arg) })
+}
+
+func (store *SQLStore) DeleteAccount(ctx context.Context, id int64) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return deleteUnusedAccount(ctx, q, id) })
+}
+
+func (store *SQLStore) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.UpdateAccount(ctx, arg) })
+}
+
+func (store *SQLStore) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.UpdateAccountStatus(ctx, arg) })
+}
+
+func (store *SQLStore) UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Hold, error) { return q.UpdateHold(ctx, arg) })
+}
+
+func (store *SQLStore) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Transfer, error) { return q.UpdateTransferStatus(ctx, arg) })
+}
+
+func (store *MemoryStore) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.AddAccountBalance(ctx, arg) })
+}
+
+func (store *MemoryStore) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.AddAccountHeldBalance(ctx, arg) })
+}
+
+func (store *MemoryStore) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Transfer, error) { return q.AddTransferReversedAmount(ctx, arg) })
+}
+
+func (store *MemoryStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.CreateAccount(ctx, arg) })
+}
+
+func (store *MemoryStore) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Entry, error) { return q.CreateEntry(ctx, arg) })
+}
+
+func (store *MemoryStore) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Hold, error) { return q.CreateHold(ctx, arg) })
+}
+
+func (store *MemoryStore) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Transfer, error) { return q.CreateTransfer(ctx, arg) })
+}
+
+func (store *MemoryStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (User, error) { return q.CreateUser(ctx, arg) })
+}
+
+func (store *MemoryStore) DeleteAccount(ctx context.Context, id int64) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return deleteUnusedAccount(ctx, q, id) })
+}
+
+func (store *MemoryStore) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.UpdateAccount(ctx, arg) })
+}
+
+func (store *MemoryStore) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Account, error) { return q.UpdateAccountStatus(ctx, arg) })
+}
+
+func (store *MemoryStore) UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Hold, error) { return q.UpdateHold(ctx, arg) })
+}
+
+func (store *MemoryStore) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	return inTx(ctx, store.txStore, func(q Querier) (Transfer, error) { return q.UpdateTransferStatus(ctx, arg) })
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"simplebank/db/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditAccountChanges(t *testing.T) {
+	forEachStore(t, testAuditAccountChanges)
+}
+
+func testAuditAccountChanges(t *testing.T, store Store) {
+	ctx := WithRequestID(WithActor(context.Background(), "auditor"), utils.RandomString(16))
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 100)
+
+	updated, err := store.UpdateAccount(ctx, UpdateAccountParams{ID: account.ID, Balance: 500})
+	require.NoError(t, err)
+	_, err = store.DeleteAccount(ctx, account.ID)
+	require.NoError(t, err)
+
+	logs := requireAuditLog(t, store, AuditEntityAccount, account.ID, 3)
+
+	require.Equal(t, AuditActionCreate, logs[0].Action)
+	require.JSONEq(t, `null`, string(logs[0].Before))
+	require.Equal(t, account.Balance, decodeAudit[Account](t, logs[0].After).Balance)
+
+	require.Equal(t, AuditActionUpdate, logs[1].Action)
+	require.Equal(t, "auditor", logs[1].Actor.String)
+	require.Equal(t, contextString(ctx, auditRequestIDKey{}), logs[1].RequestID)
+	require.Equal(t, account.Balance, decodeAudit[Account](t, logs[1].Before).Balance)
+	require.Equal(t, updated.Balance, decodeAudit[Account](t, logs[1].After).Balance)
+
+	require.Equal(t, AuditActionDelete, logs[2].Action)
+	require.Equal(t, "auditor", logs[2].Actor.String)
+	require.Equal(t, updated.Balance, decodeAudit[Account](t, logs[2].Before).Balance)
+	require.JSONEq(t, `null`, string(logs[2].After))
+}
+
+func TestAuditTransferTx(t *testing.T) {
+	forEachStore(t, testAuditTransferTx)
+}
+
+func testAuditTransferTx(t *testing.T, store Store) {
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	logs := requireAuditLog(t, store, AuditEntityAccount, account1.ID, 2)
+	require.False(t, logs[1].Actor.Valid)
+	require.Equal(t, int64(100), decodeAudit[Account](t, logs[1].Before).Balance)
+	require.Equal(t, result.FromAccount.Balance, decodeAudit[Account](t, logs[1].After).Balance)
+
+	logs = requireAuditLog(t, store, AuditEntityAccount, account2.ID, 2)
+	require.Equal(t, int64(0), decodeAudit[Account](t, logs[1].Before).Balance)
+	require.Equal(t, result.ToAccount.Balance, decodeAudit[Account](t, logs[1].After).Balance)
+
+	logs = requireAuditLog(t, store, AuditEntityTransfer, result.Transfer.ID, 1)
+	require.Equal(t, result.Transfer.Amount, decodeAudit[Transfer](t, logs[0].After).Amount)
+	requireAuditLog(t, store, AuditEntityEntry, result.FromEntry.ID, 1)
+	requireAuditLog(t, store, AuditEntityEntry, result.ToEntry.ID, 1)
+}
+
+func TestAuditUserRedactsPassword(t *testing.T) {
+	forEachStore(t, testAuditUserRedactsPassword)
+}
+
+func testAuditUserRedactsPassword(t *testing.T, store Store) {
+	user := createRandomUserIn(t, store)
+
+	logs, err := store.ListAuditLogByEntity(context.Background(), ListAuditLogByEntityParams{
+		Entity:   AuditEntityUser,
+		EntityID: user.Username,
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, user.Email, decodeAudit[User](t, logs[0].After).Email)
+	require.Empty(t, decodeAudit[User](t, logs[0].After).HashedPassword)
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	account := createFundedAccount(t, NewStore(testDB), utils.RandomCurrency(), 100)
+	entityID := strconv.FormatInt(account.ID, 10)
+
+	_, err := testDB.Exec("UPDATE audit_log SET actor = 'mallory' WHERE entity = 'account' AND entity_id = $1", entityID)
+	require.Error(t, err)
+	require.Equal(t, InsufficientPrivilege, ErrorCode(err))
+
+	_, err = testDB.Exec("DELETE FROM audit_log WHERE entity = 'account' AND entity_id = $1", entityID)
+	require.Error(t, err)
+	require.Equal(t, InsufficientPrivilege, ErrorCode(err))
+}
+
+// requireAuditLog checks that the entity has n audit log entries and returns them, oldest first.
+func requireAuditLog(t *testing.T, q Querier, entity string, entityID int64, n int) []AuditLog {
+	logs, err := q.ListAuditLogByEntity(context.Background(), ListAuditLogByEntityParams{
+		Entity:   entity,
+		EntityID: strconv.FormatInt(entityID, 10),
+		Limit:    int32(n + 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, n)
+	return logs
+}
+
+func decodeAudit[T any](t *testing.T, data json.RawMessage) T {
+	var row T
+	require.NoError(t, json.Unmarshal(data, &row))
+	return row
+}
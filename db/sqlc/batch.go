@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"simplebank/currency"
+)
+
+// MaxBatchLegs bounds the number of legs of a batch, so that a single transaction does not lock too many rows for too long.
+const MaxBatchLegs = 1000
+
+var (
+	// ErrEmptyBatch is returned when a batch has no legs.
+	ErrEmptyBatch = errors.New("batch has no legs")
+	// ErrBatchTooLarge is returned when a batch has more than MaxBatchLegs legs.
+	ErrBatchTooLarge = errors.New("batch has too many legs")
+)
+
+// BatchTransferLeg moves Amount from one account to another as part of a batch.
+type BatchTransferLeg struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        Amount `json:"amount"`
+}
+
+type BatchTransferTxParams struct {
+	Legs []BatchTransferLeg `json:"legs"`
+}
+
+// BatchTransferTxResult is the result of a batch transfer
+type BatchTransferTxResult struct {
+	// Transfers has one transfer per leg, in the order of the legs.
+	Transfers []Transfer `json:"transfers"`
+	// Entries has the debit then the credit entry of every leg, in the order of the legs.
+	Entries []Entry `json:"entries"`
+	// Accounts has every account involved in the batch after its balance was updated, in ID order.
+	Accounts []Account `json:"accounts"`
+}
+
+// BatchTransferTx moves money along every leg of arg within a single database transaction, or not at all.
+// It locks all the accounts of the batch in ID order, checks that every leg is between accounts of the same currency
+// and that the net change of every account leaves it with a non-negative available balance,
+// then records a posted transfer and two entries per leg, applies the net change to every balance once
+// and records the outbox events of every leg.
+// An account may therefore pay out money that it receives in the same batch.
+func (store txStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	if len(arg.Legs) == 0 {
+		return result, ErrEmptyBatch
+	}
+	if len(arg.Legs) > MaxBatchLegs {
+		return result, fmt.Errorf("%w: %d legs, at most %d", ErrBatchTooLarge, len(arg.Legs), MaxBatchLegs)
+	}
+	for i, leg := range arg.Legs {
+		if err := leg.Amount.Validate(); err != nil {
+			return result, fmt.Errorf("leg %d: %w", i, err)
+		}
+	}
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		result = BatchTransferTxResult{
+			Transfers: make([]Transfer, 0, len(arg.Legs)),
+			Entries:   make([]Entry, 0, 2*len(arg.Legs)),
+		}
+
+		// Step 1: Lock every account in ID order to avoid deadlocks
+		ids := make([]int64, 0, 2*len(arg.Legs))
+		for _, leg := range arg.Legs {
+			ids = append(ids, leg.FromAccountID, leg.ToAccountID)
+		}
+		accounts, err := lockAccountSet(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+
+		// Step 2: Validate the legs and what they add up to
+		net, err := netBatch(accounts, arg.Legs)
+		if err != nil {
+			return err
+		}
+
+		// Steps 3 and 4: Create the transfers and their entries
+		for _, leg := range arg.Legs {
+			transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+				FromAccountID: leg.FromAccountID,
+				ToAccountID:   leg.ToAccountID,
+				Amount:        int64(leg.Amount),
+				Status:        TransferStatusPosted,
+			})
+			if err != nil {
+				return err
+			}
+			result.Transfers = append(result.Transfers, transfer)
+
+			transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+			for _, entryArg := range []CreateEntryParams{
+				{AccountID: leg.FromAccountID, Amount: -int64(leg.Amount), Type: EntryTypeTransfer, TransferID: transferID},
+				{AccountID: leg.ToAccountID, Amount: int64(leg.Amount), Type: EntryTypeTransfer, TransferID: transferID},
+			} {
+				entry, err := q.CreateEntry(ctx, entryArg)
+				if err != nil {
+					return err
+				}
+				result.Entries = append(result.Entries, entry)
+			}
+		}
+
+		// Step 5: Update every balance once, again in ID order
+		result.Accounts = make([]Account, 0, len(accounts))
+		for _, id := range sortedAccountIDs(accounts) {
+			account := accounts[id]
+			if net[id] != 0 {
+				account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+					ID:     id,
+					Amount: net[id],
+				})
+				if err != nil {
+					return err
+				}
+			}
+			result.Accounts = append(result.Accounts, account)
+		}
+
+		// Step 6: Record the events of every leg, published once the transaction commits
+		for i, transfer := range result.Transfers {
+			err := writeTransferEvents(ctx, q, TransferTxResult{
+				Transfer:  transfer,
+				FromEntry: result.Entries[2*i],
+				ToEntry:   result.Entries[2*i+1],
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// netBatch checks every leg against the locked accounts and returns the net change of every account.
+func netBatch(accounts map[int64]Account, legs []BatchTransferLeg) (map[int64]int64, error) {
+	net := make(map[int64]int64, len(accounts))
+	for i, leg := range legs {
+		fromAccount, toAccount := accounts[leg.FromAccountID], accounts[leg.ToAccountID]
+		if fromAccount.Currency != toAccount.Currency {
+			return nil, fmt.Errorf("%w: leg %d: account %d is %s, account %d is %s",
+				ErrCurrencyMismatch, i, fromAccount.ID, fromAccount.Currency, toAccount.ID, toAccount.Currency)
+		}
+
+		var err error
+		if net[leg.FromAccountID], err = currency.Sub(net[leg.FromAccountID], int64(leg.Amount)); err != nil {
+			return nil, fmt.Errorf("leg %d: account %d: %w", i, leg.FromAccountID, err)
+		}
+		if net[leg.ToAccountID], err = currency.Add(net[leg.ToAccountID], int64(leg.Amount)); err != nil {
+			return nil, fmt.Errorf("leg %d: account %d: %w", i, leg.ToAccountID, err)
+		}
+	}
+
+	for _, id := range sortedAccountIDs(accounts) {
+		account := accounts[id]
+		if err := checkAccountsActive(account); err != nil {
+			return nil, err
+		}
+		if err := checkBalanceChange(account, net[id]); err != nil {
+			return nil, err
+		}
+		if net[id] < 0 && account.AvailableBalance < -net[id] {
+			return nil, fmt.Errorf("%w: account %d has %d available, needs %d",
+				ErrInsufficientFunds, id, account.AvailableBalance, -net[id])
+		}
+	}
+	return net, nil
+}
+
+// lockAccountSet takes a row lock on every distinct account of ids, in ID order, and returns them by ID.
+func lockAccountSet(ctx context.Context, q Querier, ids ...int64) (map[int64]Account, error) {
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		accounts[id] = Account{}
+	}
+
+	for _, id := range sortedAccountIDs(accounts) {
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("account %d: %w", id, err)
+		}
+		accounts[id] = account
+	}
+	return accounts, nil
+}
+
+func sortedAccountIDs(accounts map[int64]Account) []int64 {
+	ids := make([]int64, 0, len(accounts))
+	for id := range accounts {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simplebank/db/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchTransferTx(t *testing.T) {
+	forEachStore(t, testBatchTransferTx)
+}
+
+func testBatchTransferTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	payer := createFundedAccount(t, store, currency, 1000)
+	payees := []Account{
+		createFundedAccount(t, store, currency, 0),
+		createFundedAccount(t, store, currency, 0),
+		createFundedAccount(t, store, currency, 0),
+	}
+
+	arg := BatchTransferTxParams{}
+	for i, payee := range payees {
+		arg.Legs = append(arg.Legs, BatchTransferLeg{FromAccountID: payer.ID, ToAccountID: payee.ID, Amount: Amount(100 * (i + 1))})
+	}
+
+	result, err := store.BatchTransferTx(ctx, arg)
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, 3)
+	require.Len(t, result.Entries, 6)
+	require.Len(t, result.Accounts, 4)
+
+	for i, leg := range arg.Legs {
+		transfer := result.Transfers[i]
+		require.Equal(t, leg.FromAccountID, transfer.FromAccountID)
+		require.Equal(t, leg.ToAccountID, transfer.ToAccountID)
+		require.Equal(t, int64(leg.Amount), transfer.Amount)
+		require.Equal(t, TransferStatusPosted, transfer.Status)
+
+		debit, credit := result.Entries[2*i], result.Entries[2*i+1]
+		require.Equal(t, leg.FromAccountID, debit.AccountID)
+		require.Equal(t, -int64(leg.Amount), debit.Amount)
+		require.Equal(t, leg.ToAccountID, credit.AccountID)
+		require.Equal(t, int64(leg.Amount), credit.Amount)
+		for _, entry := range []Entry{debit, credit} {
+			require.Equal(t, EntryTypeTransfer, entry.Type)
+			require.Equal(t, sql.NullInt64{Int64: transfer.ID, Valid: true}, entry.TransferID)
+		}
+
+		requireBalance(t, store, leg.ToAccountID, int64(leg.Amount))
+	}
+	requireBalance(t, store, payer.ID, 400)
+
+	for i := 1; i < len(result.Accounts); i++ {
+		require.Less(t, result.Accounts[i-1].ID, result.Accounts[i].ID)
+	}
+	for _, account := range result.Accounts {
+		requireBalance(t, store, account.ID, account.Balance)
+	}
+}
+
+func TestBatchTransferTxNetting(t *testing.T) {
+	forEachStore(t, testBatchTransferTxNetting)
+}
+
+func testBatchTransferTxNetting(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+	account3 := createFundedAccount(t, store, currency, 0)
+
+	// account2 pays out what it receives in the same batch
+	_, err := store.BatchTransferTx(ctx, BatchTransferTxParams{Legs: []BatchTransferLeg{
+		{FromAccountID: account2.ID, ToAccountID: account3.ID, Amount: 80},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 100},
+		{FromAccountID: account3.ID, ToAccountID: account1.ID, Amount: 30},
+	}})
+	require.NoError(t, err)
+	requireBalance(t, store, account1.ID, 30)
+	requireBalance(t, store, account2.ID, 20)
+	requireBalance(t, store, account3.ID, 50)
+
+	// but not more than that
+	_, err = store.BatchTransferTx(ctx, BatchTransferTxParams{Legs: []BatchTransferLeg{
+		{FromAccountID: account2.ID, ToAccountID: account3.ID, Amount: 31},
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+	}})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// held funds are not available either
+	_, err = store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account3.ID, ToAccountID: account1.ID, Amount: 50})
+	require.NoError(t, err)
+	_, err = store.BatchTransferTx(ctx, BatchTransferTxParams{Legs: []BatchTransferLeg{
+		{FromAccountID: account3.ID, ToAccountID: account2.ID, Amount: 1},
+	}})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	requireBalance(t, store, account1.ID, 30)
+	requireBalance(t, store, account2.ID, 20)
+	requireBalance(t, store, account3.ID, 50)
+}
+
+func TestBatchTransferTxAllOrNothing(t *testing.T) {
+	forEachStore(t, testBatchTransferTxAllOrNothing)
+}
+
+func testBatchTransferTxAllOrNothing(t *testing.T, store Store) {
+	ctx := context.Background()
+	account1 := createFundedAccount(t, store, "USD", 100)
+	account2 := createFundedAccount(t, store, "USD", 0)
+	account3 := createFundedAccount(t, store, "EUR", 0)
+	valid := BatchTransferLeg{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10}
+
+	testCases := []struct {
+		name string
+		legs []BatchTransferLeg
+		err  error
+	}{
+		{name: "Empty", legs: nil, err: ErrEmptyBatch},
+		{name: "TooLarge", legs: make([]BatchTransferLeg, MaxBatchLegs+1), err: ErrBatchTooLarge},
+		{
+			name: "InvalidAmount",
+			legs: []BatchTransferLeg{valid, {FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: -5}},
+			err:  ErrInvalidAmount,
+		},
+		{
+			name: "CurrencyMismatch",
+			legs: []BatchTransferLeg{valid, {FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 10}},
+			err:  ErrCurrencyMismatch,
+		},
+		{
+			name: "InsufficientFunds",
+			legs: []BatchTransferLeg{valid, valid, {FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 81}},
+			err:  ErrInsufficientFunds,
+		},
+		{
+			name: "UnknownAccount",
+			legs: []BatchTransferLeg{valid, {FromAccountID: account1.ID, ToAccountID: -1, Amount: 10}},
+			err:  sql.ErrNoRows,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.BatchTransferTx(ctx, BatchTransferTxParams{Legs: tc.legs})
+			require.ErrorIs(t, err, tc.err)
+
+			requireBalance(t, store, account1.ID, 100)
+			requireBalance(t, store, account2.ID, 0)
+			transfers, err := store.ListTransfersFromAccount(ctx, ListTransfersFromAccountParams{FromAccountID: account1.ID, Limit: 10})
+			require.NoError(t, err)
+			require.Empty(t, transfers)
+		})
+	}
+}
+
+func TestBatchTransferTxDeadlock(t *testing.T) {
+	forEachStore(t, testBatchTransferTxDeadlock)
+}
+
+func testBatchTransferTxDeadlock(t *testing.T, store Store) {
+	currency := utils.RandomCurrency()
+	accounts := []Account{
+		createFundedAccount(t, store, currency, 1000),
+		createFundedAccount(t, store, currency, 1000),
+		createFundedAccount(t, store, currency, 1000),
+	}
+
+	// every batch goes around the accounts in a different direction and from a different start
+	n := 6
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		var legs []BatchTransferLeg
+		for j := range accounts {
+			from := accounts[(i+j)%len(accounts)]
+			to := accounts[(i+j+1)%len(accounts)]
+			if i%2 == 1 {
+				from, to = to, from
+			}
+			legs = append(legs, BatchTransferLeg{FromAccountID: from.ID, ToAccountID: to.ID, Amount: 10})
+		}
+
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{Legs: legs})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// every account pays and receives the same amount in every batch
+	for _, account := range accounts {
+		requireBalance(t, store, account.ID, 1000)
+	}
+}
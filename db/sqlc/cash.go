@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type DepositTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Amount    Amount `json:"amount"`
+	// ExternalRef identifies the deposit in the system the money comes from, e.g. a card payment ID.
+	ExternalRef string `json:"external_ref"`
+}
+
+type WithdrawTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Amount    Amount `json:"amount"`
+	// ExternalRef identifies the withdrawal in the system the money goes to, e.g. a payout ID.
+	ExternalRef string `json:"external_ref"`
+}
+
+// CashTxResult is the result of a deposit or a withdrawal.
+type CashTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// DepositTx credits an account with money coming from outside of the bank.
+// It writes a deposit entry and adds the amount to the balance within a single database transaction.
+func (store txStore) DepositTx(ctx context.Context, arg DepositTxParams) (CashTxResult, error) {
+	if err := arg.Amount.Validate(); err != nil {
+		return CashTxResult{}, fmt.Errorf("deposit: %w", err)
+	}
+	return store.cashTx(ctx, arg.AccountID, int64(arg.Amount), EntryTypeDeposit, arg.ExternalRef)
+}
+
+// WithdrawTx debits an account with money leaving the bank.
+// It locks the account, checks that the available balance covers the amount, then writes a withdrawal entry
+// and subtracts the amount from the balance within a single database transaction.
+func (store txStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (CashTxResult, error) {
+	if err := arg.Amount.Validate(); err != nil {
+		return CashTxResult{}, fmt.Errorf("withdrawal: %w", err)
+	}
+	return store.cashTx(ctx, arg.AccountID, -int64(arg.Amount), EntryTypeWithdrawal, arg.ExternalRef)
+}
+
+// cashTx moves amount into (positive) or out of (negative) an active account,
+// never below a zero available balance nor beyond the largest balance a bigint holds.
+// It records an EntryPosted event for the entry.
+func (store txStore) cashTx(ctx context.Context, accountID int64, amount int64, entryType EntryType, externalRef string) (CashTxResult, error) {
+	var result CashTxResult
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		account, err := q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if err := checkAccountsActive(account); err != nil {
+			return err
+		}
+		if err := checkBalanceChange(account, amount); err != nil {
+			return err
+		}
+		if account.AvailableBalance < -amount {
+			return fmt.Errorf("%w: account %d has %d available, needs %d",
+				ErrInsufficientFunds, account.ID, account.AvailableBalance, -amount)
+		}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:   accountID,
+			Amount:      amount,
+			Type:        entryType,
+			ExternalRef: sql.NullString{String: externalRef, Valid: externalRef != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     accountID,
+			Amount: amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return writeEvent(ctx, q, EventTypeEntryPosted, result.Entry.ID, result.Entry)
+	})
+
+	return result, err
+}
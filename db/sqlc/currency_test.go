@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"simplebank/currency"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAccountCurrency(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		owner := createRandomUserIn(t, store).Username
+
+		account, err := store.CreateAccount(ctx, CreateAccountParams{Owner: owner, Currency: "JPY"})
+		require.NoError(t, err)
+		require.Equal(t, "JPY", account.Currency)
+
+		for _, code := range []string{"XXX", "usd", ""} {
+			_, err = store.CreateAccount(ctx, CreateAccountParams{Owner: owner, Currency: code})
+			require.ErrorIs(t, err, ErrForeignKeyViolation, code)
+		}
+	})
+}
+
+// The currencies table is seeded by a migration and must list the same currencies as the currency package.
+func TestCurrencyMigrationMatchesRegistry(t *testing.T) {
+	data, err := os.ReadFile("../migration/000012_add_currencies.up.sql")
+	require.NoError(t, err)
+
+	var seeded []currency.Currency
+	for _, match := range regexp.MustCompile(`\('([A-Z]{3})', (\d)\)`).FindAllStringSubmatch(string(data), -1) {
+		exponent, err := strconv.Atoi(match[2])
+		require.NoError(t, err)
+		seeded = append(seeded, currency.Currency{Code: match[1], Exponent: exponent})
+	}
+	require.Equal(t, currency.All(), seeded)
+}
@@ -7,33 +7,64 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
-INSERT INTO entries (account_id, amount) 
-VALUES ($1, $2)
-RETURNING id, account_id, amount, created_at
+INSERT INTO entries (account_id, amount, type, transfer_id, external_ref)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, account_id, amount, created_at, transfer_id, type, external_ref
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID   int64          `json:"account_id"`
+	Amount      int64          `json:"amount"`
+	Type        EntryType      `json:"type"`
+	TransferID  sql.NullInt64  `json:"transfer_id"`
+	ExternalRef sql.NullString `json:"external_ref"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.Type,
+		arg.TransferID,
+		arg.ExternalRef,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Type,
+		&i.ExternalRef,
 	)
 	return i, err
 }
 
+const getEntriesTotalSince = `-- name: GetEntriesTotalSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at >= $2
+`
+
+type GetEntriesTotalSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	Since     time.Time `json:"since"`
+}
+
+func (q *Queries) GetEntriesTotalSince(ctx context.Context, arg GetEntriesTotalSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getEntriesTotalSince, arg.AccountID, arg.Since)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries WHERE id = $1 LIMIT 1
+SELECT id, account_id, amount, created_at, transfer_id, type, external_ref FROM entries WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
@@ -44,12 +75,15 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.Type,
+		&i.ExternalRef,
 	)
 	return i, err
 }
 
 const listEntriesByAccount = `-- name: ListEntriesByAccount :many
-SELECT id, account_id, amount, created_at FROM entries 
+SELECT id, account_id, amount, created_at, transfer_id, type, external_ref FROM entries 
 WHERE account_id = $1
 ORDER BY id
 LIMIT $2 OFFSET $3
@@ -75,6 +109,160 @@ func (q *Queries) ListEntriesByAccount(ctx context.Context, arg ListEntriesByAcc
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.Type,
+			&i.ExternalRef,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByAccountPage = `-- name: ListEntriesByAccountPage :many
+SELECT id, account_id, amount, created_at, transfer_id, type, external_ref FROM entries
+WHERE account_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListEntriesByAccountPageParams struct {
+	AccountID      int64     `json:"account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	PageSize       int32     `json:"page_size"`
+}
+
+func (q *Queries) ListEntriesByAccountPage(ctx context.Context, arg ListEntriesByAccountPageParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByAccountPage,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Type,
+			&i.ExternalRef,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesByTransfer = `-- name: ListEntriesByTransfer :many
+SELECT id, account_id, amount, created_at, transfer_id, type, external_ref FROM entries
+WHERE transfer_id = $1::bigint
+ORDER BY amount
+`
+
+// Returns the legs of a transfer: the debit of the source account, then the credit of the destination account.
+func (q *Queries) ListEntriesByTransfer(ctx context.Context, transferID int64) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesByTransfer, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Type,
+			&i.ExternalRef,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementEntries = `-- name: ListStatementEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id, e.type, e.external_ref,
+  t.from_account_id, t.to_account_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = $1
+  AND e.created_at >= $2
+  AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	FromTime  time.Time `json:"from_time"`
+	ToTime    time.Time `json:"to_time"`
+}
+
+type ListStatementEntriesRow struct {
+	ID            int64          `json:"id"`
+	AccountID     int64          `json:"account_id"`
+	Amount        int64          `json:"amount"`
+	CreatedAt     time.Time      `json:"created_at"`
+	TransferID    sql.NullInt64  `json:"transfer_id"`
+	Type          EntryType      `json:"type"`
+	ExternalRef   sql.NullString `json:"external_ref"`
+	FromAccountID sql.NullInt64  `json:"from_account_id"`
+	ToAccountID   sql.NullInt64  `json:"to_account_id"`
+}
+
+func (q *Queries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementEntries, arg.AccountID, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListStatementEntriesRow{}
+	for rows.Next() {
+		var i ListStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Type,
+			&i.ExternalRef,
+			&i.FromAccountID,
+			&i.ToAccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -11,7 +11,7 @@ import (
 
 func TestCreateEntry_Success(t *testing.T) {
     arg := CreateAccountParams {
-        Owner: utils.RandomOwner(),
+        Owner: createRandomUser(t).Username,
         Balance: utils.RandomMoney(),
         Currency: utils.RandomCurrency(),
     }
@@ -28,6 +28,7 @@ func TestCreateEntry_Success(t *testing.T) {
     createEntryArg := CreateEntryParams{
         AccountID: accountID.ID,
         Amount:    amount,
+        Type:      EntryTypeAdjustment,
     }
 
     createdEntry, err := testQueries.CreateEntry(context.Background(), createEntryArg)
@@ -51,7 +52,7 @@ func TestCreateEntry_Success(t *testing.T) {
 func TestCreateEntry_Failure(t *testing.T) {
 
     account := CreateAccountParams {
-        Owner: utils.RandomOwner(),
+        Owner: createRandomUser(t).Username,
         Balance: utils.RandomMoney(),
         Currency: utils.RandomCurrency(),
     }
@@ -68,6 +69,7 @@ func TestCreateEntry_Failure(t *testing.T) {
     entry := CreateEntryParams{
         AccountID: accountID.ID,
         Amount:    amount,
+        Type:      EntryTypeAdjustment,
     }
 
     createdEntry, err := testQueries.CreateEntry(context.Background(), entry)
@@ -84,7 +86,7 @@ func TestCreateEntry_Failure(t *testing.T) {
 func TestListEntriesByAccount(t *testing.T) {
 
 	account := CreateAccountParams {
-		Owner: utils.RandomOwner(),
+		Owner: createRandomUser(t).Username,
 		Balance: utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -99,6 +101,7 @@ func TestListEntriesByAccount(t *testing.T) {
 		entryID, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
 			AccountID: accountID.ID,
 			Amount:    amount,
+			Type:      EntryTypeAdjustment,
 		})
 		require.NoError(t, err)
 		require.NotZero(t, entryID)
@@ -133,7 +136,7 @@ func TestListEntriesByAccount(t *testing.T) {
 func TestGetEntry_Success(t *testing.T) {
 	// Create a test entry
 	account1 := CreateAccountParams {
-		Owner: utils.RandomOwner(),
+		Owner: createRandomUser(t).Username,
 		Balance: utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -146,6 +149,7 @@ func TestGetEntry_Success(t *testing.T) {
 	entry := CreateEntryParams{
 		AccountID: accountID.ID,
 		Amount:    utils.RandomMoney(),
+		Type:      EntryTypeAdjustment,
 	}
 
 	// insert the entry into the database
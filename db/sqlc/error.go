@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes the application reacts to.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	NumericValueOutOfRange    = "22003"
+	InvalidTextRepresentation = "22P02"
+
+	NotNullViolation    = "23502"
+	ForeignKeyViolation = "23503"
+	UniqueViolation     = "23505"
+	CheckViolation      = "23514"
+
+	InsufficientPrivilege = "42501"
+
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
+)
+
+var (
+	// ErrUniqueViolation matches errors caused by a duplicate key, e.g. a second account with the same owner and currency.
+	ErrUniqueViolation = errors.New("unique violation")
+	// ErrForeignKeyViolation matches errors caused by a missing or still referenced row, e.g. an account for an unknown owner.
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+)
+
+// ConstraintError is returned by the store when a write violates a unique or foreign key constraint.
+// It matches ErrUniqueViolation or ErrForeignKeyViolation with errors.Is, and the underlying *pq.Error with errors.As.
+type ConstraintError struct {
+	Kind       error
+	Constraint string
+	Err        *pq.Error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConstraintError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// ErrorCode returns the Postgres error code wrapped in err, or an empty string
+// if err did not come from Postgres.
+func ErrorCode(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
+// constraintError turns unique and foreign key violations into a *ConstraintError,
+// wraps a bigint overflow in ErrAmountOverflow, and returns any other error unchanged.
+func constraintError(err error) error {
+	var constraintErr *ConstraintError
+	if err == nil || errors.As(err, &constraintErr) || errors.Is(err, ErrAmountOverflow) {
+		return err
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch string(pqErr.Code) {
+	case UniqueViolation:
+		return &ConstraintError{Kind: ErrUniqueViolation, Constraint: pqErr.Constraint, Err: pqErr}
+	case ForeignKeyViolation:
+		return &ConstraintError{Kind: ErrForeignKeyViolation, Constraint: pqErr.Constraint, Err: pqErr}
+	case NumericValueOutOfRange:
+		return fmt.Errorf("%w: %w", ErrAmountOverflow, err)
+	}
+	return err
+}
+
+// CreateIdempotencyKey can violate a constraint outside of a transaction,
+// so the SQL store surfaces its errors as *ConstraintError.
+// The audited writes run in a transaction of their own, which does the same.
+func (store *SQLStore) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	key, err := store.Queries.CreateIdempotencyKey(ctx, arg)
+	return key, constraintError(err)
+}
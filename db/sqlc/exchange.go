@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"simplebank/fx"
+)
+
+var (
+	// ErrPartialExchangeReversal is returned when asked to reverse part of a cross-currency transfer.
+	ErrPartialExchangeReversal = errors.New("cross-currency transfers are reversed in full")
+	// ErrNoRateProvider is returned by ExchangeTransferTx on a store created without an fx.RateProvider.
+	ErrNoRateProvider = errors.New("store has no fx rate provider")
+)
+
+type ExchangeTransferTxParams struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        Amount `json:"amount"`
+}
+
+// ExchangeTransferTx sends Amount, in the currency of the source account, to an account holding another currency.
+// It locks both accounts and asks the rate provider of the store for a quote between their currencies,
+// so that the rate is never taken from the caller. It then checks that the available balance of the source account
+// covers the amount, records a transfer with both amounts and the rate, debits the source account in its currency
+// and credits the destination account in its own, all within a single database transaction.
+// It fails with fx.ErrInvalidQuote when the quote does not price the requested exchange at its own rate.
+func (store txStore) ExchangeTransferTx(ctx context.Context, arg ExchangeTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	if err := arg.Amount.Validate(); err != nil {
+		return result, err
+	}
+	if store.rates == nil {
+		return result, ErrNoRateProvider
+	}
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		quote, err := store.rates.Quote(ctx, fromAccount.Currency, toAccount.Currency, int64(arg.Amount))
+		if err != nil {
+			return err
+		}
+		if err := checkQuote(quote, fromAccount, toAccount, arg.Amount); err != nil {
+			return err
+		}
+
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        quote.SourceAmount,
+			Status:        TransferStatusPosted,
+			ToAmount:      sql.NullInt64{Int64: quote.TargetAmount, Valid: true},
+			ExchangeRate:  sql.NullString{String: formatRate(quote.Rate), Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// checkQuote checks that quote prices amount of the currency of fromAccount in the currency of toAccount.
+// The provider is trusted, but a quote that does not add up must never reach the ledger.
+func checkQuote(quote fx.Quote, fromAccount, toAccount Account, amount Amount) error {
+	if err := quote.Validate(time.Now()); err != nil {
+		return err
+	}
+	if quote.SourceCurrency != fromAccount.Currency || quote.TargetCurrency != toAccount.Currency ||
+		quote.SourceAmount != int64(amount) {
+		return fmt.Errorf("%w: quoted %d %s/%s for %d %s from account %d to %s account %d",
+			fx.ErrInvalidQuote, quote.SourceAmount, quote.SourceCurrency, quote.TargetCurrency,
+			amount, fromAccount.Currency, fromAccount.ID, toAccount.Currency, toAccount.ID)
+	}
+	return nil
+}
+
+// IsExchange reports whether the transfer converts between two currencies.
+func (transfer Transfer) IsExchange() bool {
+	return transfer.ToAmount.Valid
+}
+
+// CreditAmount returns the amount credited to the destination account, in its currency.
+func (transfer Transfer) CreditAmount() int64 {
+	if transfer.ToAmount.Valid {
+		return transfer.ToAmount.Int64
+	}
+	return transfer.Amount
+}
+
+// reversalParams describes the transfer that sends amount of original back,
+// at the original amounts when original converted between currencies.
+func reversalParams(original Transfer, amount int64, reason string) CreateTransferParams {
+	arg := CreateTransferParams{
+		FromAccountID: original.ToAccountID,
+		ToAccountID:   original.FromAccountID,
+		Amount:        amount,
+		ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+		Reason:        sql.NullString{String: reason, Valid: reason != ""},
+		Status:        TransferStatusPosted,
+	}
+	if original.IsExchange() {
+		arg.Amount = original.ToAmount.Int64
+		arg.ToAmount = sql.NullInt64{Int64: original.Amount, Valid: true}
+		arg.ExchangeRate = sql.NullString{String: inverseRate(original.ExchangeRate.String), Valid: true}
+	}
+	return arg
+}
+
+// exchangeRateDecimals is the precision of the exchange_rate column. A rate with more decimals, or with no finite
+// decimal expansion like the inverse of most rates, is rounded there; the amounts of the transfer stay exact.
+const exchangeRateDecimals = 12
+
+// inverseRate returns the rate of the opposite currency pair.
+// The database only stores positive rates, so a rate that does not parse is a bug and is kept as is.
+func inverseRate(rate string) string {
+	r, err := fx.ParseRate(rate)
+	if err != nil {
+		return rate
+	}
+	return formatRate(r.Inverse())
+}
+
+// formatRate writes rate as a decimal for the exchange_rate column.
+func formatRate(rate fx.Rate) string {
+	return rate.Decimal(exchangeRateDecimals)
+}
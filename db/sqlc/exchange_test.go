@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simplebank/db/utils"
+	"simplebank/fx"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// forEachExchangeStore runs test against every Store implementation, created with rates as their rate provider.
+func forEachExchangeStore(t *testing.T, rates fx.RateProvider, test func(t *testing.T, store Store)) {
+	stores := []struct {
+		name     string
+		newStore func() Store
+	}{
+		{name: "sql", newStore: func() Store { return NewStoreWithRateProvider(testDB, rates) }},
+		{name: "memory", newStore: func() Store { return NewMemoryStoreWithRateProvider(rates) }},
+	}
+
+	for _, s := range stores {
+		s := s
+		t.Run(s.name, func(t *testing.T) {
+			test(t, s.newStore())
+		})
+	}
+}
+
+func newRateProvider(t *testing.T) fx.RateProvider {
+	provider, err := fx.NewStaticProvider(map[string]string{"USD/EUR": "0.9"}, time.Minute)
+	require.NoError(t, err)
+	return provider
+}
+
+// forgedRateProvider alters the quotes of a real provider, standing in for a provider with a bug.
+type forgedRateProvider struct {
+	fx.RateProvider
+	forge func(quote *fx.Quote)
+}
+
+func (provider forgedRateProvider) Quote(ctx context.Context, source, target string, sourceAmount int64) (fx.Quote, error) {
+	quote, err := provider.RateProvider.Quote(ctx, source, target, sourceAmount)
+	if err == nil {
+		provider.forge(&quote)
+	}
+	return quote, err
+}
+
+func TestExchangeTransferTx(t *testing.T) {
+	forEachExchangeStore(t, newRateProvider(t), testExchangeTransferTx)
+}
+
+func testExchangeTransferTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	account1 := createFundedAccount(t, store, utils.USD, 1000)
+	account2 := createFundedAccount(t, store, utils.EUR, 0)
+
+	result, err := store.ExchangeTransferTx(ctx, ExchangeTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 500})
+	require.NoError(t, err)
+
+	transfer := result.Transfer
+	require.Equal(t, int64(500), transfer.Amount)
+	require.Equal(t, sql.NullInt64{Int64: 450, Valid: true}, transfer.ToAmount)
+	require.Equal(t, sql.NullString{String: "0.9", Valid: true}, transfer.ExchangeRate)
+	require.True(t, transfer.IsExchange())
+	require.Equal(t, int64(450), transfer.CreditAmount())
+
+	// each account moves in its own currency
+	require.Equal(t, int64(-500), result.FromEntry.Amount)
+	require.Equal(t, int64(450), result.ToEntry.Amount)
+	requireBalance(t, store, account1.ID, 500)
+	requireBalance(t, store, account2.ID, 450)
+
+	got, err := store.GetTransfer(ctx, transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, transfer.ToAmount, got.ToAmount)
+	require.Equal(t, transfer.ExchangeRate, got.ExchangeRate)
+
+	// a reversal sends back exactly what was received, at the original amounts
+	_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: transfer.ID, Amount: 100})
+	require.ErrorIs(t, err, ErrPartialExchangeReversal)
+
+	reversal, err := store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(450), reversal.Reversal.Transfer.Amount)
+	require.Equal(t, sql.NullInt64{Int64: 500, Valid: true}, reversal.Reversal.Transfer.ToAmount)
+	require.Equal(t, int64(500), reversal.OriginalTransfer.ReversedAmount)
+	requireBalance(t, store, account1.ID, 1000)
+	requireBalance(t, store, account2.ID, 0)
+}
+
+func TestExchangeTransferTxErrors(t *testing.T) {
+	forEachExchangeStore(t, newRateProvider(t), func(t *testing.T, store Store) {
+		ctx := context.Background()
+		account1 := createFundedAccount(t, store, utils.USD, 100)
+		account2 := createFundedAccount(t, store, utils.EUR, 0)
+		account3 := createFundedAccount(t, store, utils.CAD, 0)
+
+		// the provider must quote the currencies of both accounts
+		_, err := store.ExchangeTransferTx(ctx, ExchangeTransferTxParams{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 50})
+		require.ErrorIs(t, err, fx.ErrUnsupportedPair)
+
+		_, err = store.ExchangeTransferTx(ctx, ExchangeTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 101})
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		_, err = store.ExchangeTransferTx(ctx, ExchangeTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 0})
+		require.ErrorIs(t, err, ErrInvalidAmount)
+
+		requireBalance(t, store, account1.ID, 100)
+		requireBalance(t, store, account2.ID, 0)
+
+		// a plain transfer still refuses to mix currencies
+		_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+		require.ErrorIs(t, err, ErrCurrencyMismatch)
+	})
+}
+
+func TestExchangeTransferTxNoRateProvider(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		account1 := createFundedAccount(t, store, utils.USD, 100)
+		account2 := createFundedAccount(t, store, utils.EUR, 0)
+
+		_, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 50})
+		require.ErrorIs(t, err, ErrNoRateProvider)
+		requireBalance(t, store, account1.ID, 100)
+	})
+}
+
+func TestExchangeTransferTxForgedQuote(t *testing.T) {
+	testCases := []struct {
+		name  string
+		forge func(quote *fx.Quote)
+		err   error
+	}{
+		{
+			// a rate that does not match the amounts would create money
+			name:  "Rate",
+			forge: func(quote *fx.Quote) { quote.Rate = quote.Rate.Inverse() },
+			err:   fx.ErrInvalidQuote,
+		},
+		{
+			name:  "TargetAmount",
+			forge: func(quote *fx.Quote) { quote.TargetAmount = 1_000_000 },
+			err:   fx.ErrInvalidQuote,
+		},
+		{
+			name: "SourceAmount",
+			forge: func(quote *fx.Quote) {
+				quote.SourceAmount = 1
+				quote.TargetAmount = 0
+			},
+			err: fx.ErrInvalidQuote,
+		},
+		{
+			name: "Currency",
+			forge: func(quote *fx.Quote) {
+				quote.SourceCurrency, quote.TargetCurrency = quote.TargetCurrency, quote.SourceCurrency
+			},
+			err: fx.ErrInvalidQuote,
+		},
+		{
+			name:  "Expired",
+			forge: func(quote *fx.Quote) { quote.ExpiresAt = time.Now().Add(-time.Second) },
+			err:   fx.ErrQuoteExpired,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			provider := forgedRateProvider{RateProvider: newRateProvider(t), forge: tc.forge}
+			forEachExchangeStore(t, provider, func(t *testing.T, store Store) {
+				account1 := createFundedAccount(t, store, utils.USD, 100)
+				account2 := createFundedAccount(t, store, utils.EUR, 0)
+
+				_, err := store.ExchangeTransferTx(context.Background(), ExchangeTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 50})
+				require.ErrorIs(t, err, tc.err)
+
+				requireBalance(t, store, account1.ID, 100)
+				requireBalance(t, store, account2.ID, 0)
+			})
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// expireHoldsBatchSize is the number of holds ExpireHolds settles per transaction.
+const expireHoldsBatchSize = 100
+
+var (
+	// ErrHoldNotActive is returned when asked to release or capture a hold that was already settled.
+	ErrHoldNotActive = errors.New("hold is not active")
+	// ErrHoldExpired is returned when asked to capture a hold after its expiry.
+	ErrHoldExpired = errors.New("hold has expired")
+	// ErrCaptureExceedsHold is returned when asked to capture more than the hold reserved.
+	ErrCaptureExceedsHold = errors.New("capture exceeds hold")
+	// ErrInvalidExpiry is returned when asked to place a hold that expires in the past.
+	ErrInvalidExpiry = errors.New("hold expiry is not in the future")
+)
+
+type PlaceHoldParams struct {
+	AccountID int64     `json:"account_id"`
+	Amount    Amount    `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type HoldTxResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+type CaptureHoldParams struct {
+	HoldID      int64 `json:"hold_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// Amount is the part of the hold to capture. Zero captures all of it.
+	Amount Amount `json:"amount"`
+}
+
+type CaptureHoldResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// PlaceHold reserves funds on an account without moving them, like a card authorization.
+// It locks the account, checks that it is active and that the available balance covers the amount, then creates an active hold
+// and adds its amount to the held balance, all within a single database transaction.
+// The hold lasts until it is released, captured or expires.
+func (store txStore) PlaceHold(ctx context.Context, arg PlaceHoldParams) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	if err := arg.Amount.Validate(); err != nil {
+		return result, fmt.Errorf("hold: %w", err)
+	}
+	if !arg.ExpiresAt.After(time.Now()) {
+		return result, fmt.Errorf("%w: %s", ErrInvalidExpiry, arg.ExpiresAt)
+	}
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkAccountsActive(account); err != nil {
+			return err
+		}
+		if account.AvailableBalance < int64(arg.Amount) {
+			return fmt.Errorf("%w: account %d has %d available, needs %d",
+				ErrInsufficientFunds, account.ID, account.AvailableBalance, arg.Amount)
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID: arg.AccountID,
+			Amount:    int64(arg.Amount),
+			ExpiresAt: arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: int64(arg.Amount),
+			ID:     arg.AccountID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ReleaseHold gives the funds reserved by an active hold back to its account.
+// It fails with ErrHoldNotActive when the hold was already released, captured or expired.
+func (store txStore) ReleaseHold(ctx context.Context, holdID int64) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		var err error
+		result, err = settleHold(ctx, q, holdID, HoldStatusReleased)
+		return err
+	})
+
+	return result, err
+}
+
+// CaptureHold turns an active hold into a transfer from its account to arg.ToAccountID.
+// It locks the hold, releases the whole reserved amount, then transfers the captured part like TransferTx does,
+// so that a partial capture gives the rest back to the account.
+// It fails with ErrHoldNotActive when the hold was already settled, and with ErrHoldExpired after its expiry.
+func (store txStore) CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error) {
+	var result CaptureHoldResult
+	if err := arg.Amount.validateOptional(); err != nil {
+		return result, fmt.Errorf("capture: %w", err)
+	}
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != HoldStatusActive {
+			return fmt.Errorf("%w: hold %d is %s", ErrHoldNotActive, hold.ID, hold.Status)
+		}
+		if !hold.ExpiresAt.After(time.Now()) {
+			return fmt.Errorf("%w: hold %d expired at %s", ErrHoldExpired, hold.ID, hold.ExpiresAt)
+		}
+
+		amount := int64(arg.Amount)
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return fmt.Errorf("%w: hold %d reserves %d, cannot capture %d", ErrCaptureExceedsHold, hold.ID, hold.Amount, amount)
+		}
+
+		// Lock both accounts before touching either, in the same order as TransferTx
+		_, _, err = lockAccounts(ctx, q, hold.AccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		// Release the hold first, so that the transfer can spend the funds it reserved
+		_, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: -hold.Amount,
+			ID:     hold.AccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        amount,
+			Status:        TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			Status:         HoldStatusCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+			ID:             hold.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ExpireHolds releases every active hold that expired at or before asOf and marks it expired.
+// It works in batches of one transaction each and skips holds that are being released or captured concurrently,
+// so it is safe to run from several processes. It returns the number of holds it expired.
+func (store txStore) ExpireHolds(ctx context.Context, asOf time.Time) (int, error) {
+	expired := 0
+	for {
+		var n int
+		err := store.execTx(ctx, nil, func(q Querier) error {
+			holds, err := q.ListExpiredHoldsForUpdate(ctx, ListExpiredHoldsForUpdateParams{
+				ExpiresBefore: asOf,
+				BatchSize:     expireHoldsBatchSize,
+			})
+			if err != nil {
+				return err
+			}
+
+			// release in account order, like lockAccounts, to avoid deadlocks with transfers
+			sort.SliceStable(holds, func(i, j int) bool { return holds[i].AccountID < holds[j].AccountID })
+			for _, hold := range holds {
+				if _, err := releaseHold(ctx, q, hold, HoldStatusExpired); err != nil {
+					return err
+				}
+			}
+			n = len(holds)
+			return nil
+		})
+		if err != nil {
+			return expired, err
+		}
+
+		expired += n
+		if n < expireHoldsBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// settleHold locks an active hold and releases it with the given final status.
+func settleHold(ctx context.Context, q Querier, holdID int64, status HoldStatus) (HoldTxResult, error) {
+	hold, err := q.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return HoldTxResult{}, err
+	}
+	if hold.Status != HoldStatusActive {
+		return HoldTxResult{}, fmt.Errorf("%w: hold %d is %s", ErrHoldNotActive, hold.ID, hold.Status)
+	}
+	return releaseHold(ctx, q, hold, status)
+}
+
+// releaseHold gives the amount of a locked, active hold back to its account and sets its final status.
+func releaseHold(ctx context.Context, q Querier, hold Hold, status HoldStatus) (HoldTxResult, error) {
+	var result HoldTxResult
+
+	var err error
+	result.Account, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		Amount: -hold.Amount,
+		ID:     hold.AccountID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+		Status: status,
+		ID:     hold.ID,
+	})
+	return result, err
+}
@@ -0,0 +1,225 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simplebank/db/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceHold(t *testing.T) {
+	forEachStore(t, testPlaceHold)
+}
+
+func testPlaceHold(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+	expiresAt := time.Now().Add(time.Hour)
+
+	result, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 70, ExpiresAt: expiresAt})
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, result.Hold.AccountID)
+	require.Equal(t, int64(70), result.Hold.Amount)
+	require.Equal(t, HoldStatusActive, result.Hold.Status)
+	require.WithinDuration(t, expiresAt, result.Hold.ExpiresAt, time.Millisecond)
+	require.Equal(t, int64(30), result.Account.AvailableBalance)
+
+	// GetAccount reports the ledger balance and what is left to spend
+	requireAvailableBalance(t, store, account1.ID, 100, 30)
+
+	holds, err := store.ListActiveHoldsByAccount(ctx, account1.ID)
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	require.Equal(t, result.Hold.ID, holds[0].ID)
+
+	// held funds cannot be spent
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 31})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account1.ID, Amount: 31})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 31, ExpiresAt: expiresAt})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+	requireAvailableBalance(t, store, account1.ID, 70, 0)
+
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 0, ExpiresAt: expiresAt})
+	require.ErrorIs(t, err, ErrInvalidAmount)
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 1, ExpiresAt: time.Now().Add(-time.Second)})
+	require.ErrorIs(t, err, ErrInvalidExpiry)
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: -1, Amount: 1, ExpiresAt: expiresAt})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestReleaseHold(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		account := createFundedAccount(t, store, utils.RandomCurrency(), 100)
+
+		placed, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account.ID, Amount: 40, ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+
+		result, err := store.ReleaseHold(ctx, placed.Hold.ID)
+		require.NoError(t, err)
+		require.Equal(t, HoldStatusReleased, result.Hold.Status)
+		require.Zero(t, result.Hold.CapturedAmount)
+		require.Equal(t, int64(100), result.Account.AvailableBalance)
+		requireAvailableBalance(t, store, account.ID, 100, 100)
+
+		_, err = store.ReleaseHold(ctx, placed.Hold.ID)
+		require.ErrorIs(t, err, ErrHoldNotActive)
+		_, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account.ID})
+		require.ErrorIs(t, err, ErrHoldNotActive)
+		requireAvailableBalance(t, store, account.ID, 100, 100)
+
+		_, err = store.ReleaseHold(ctx, -1)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestCaptureHold(t *testing.T) {
+	forEachStore(t, testCaptureHold)
+}
+
+func testCaptureHold(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	placed, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 60, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	_, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account2.ID, Amount: 61})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+	_, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account2.ID, Amount: -1})
+	require.ErrorIs(t, err, ErrInvalidAmount)
+	requireAvailableBalance(t, store, account1.ID, 100, 40)
+
+	// a partial capture moves the captured part and gives the rest back
+	result, err := store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account2.ID, Amount: 45})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.Equal(t, int64(45), result.Hold.CapturedAmount)
+	require.Equal(t, sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true}, result.Hold.TransferID)
+	require.Equal(t, int64(45), result.Transfer.Transfer.Amount)
+	require.Equal(t, TransferStatusPosted, result.Transfer.Transfer.Status)
+	require.Equal(t, int64(-45), result.Transfer.FromEntry.Amount)
+	require.Equal(t, int64(45), result.Transfer.ToEntry.Amount)
+
+	requireAvailableBalance(t, store, account1.ID, 55, 55)
+	requireAvailableBalance(t, store, account2.ID, 45, 45)
+
+	_, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account2.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	// zero captures the whole hold
+	placed, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 55, ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	result, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account2.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(55), result.Hold.CapturedAmount)
+	requireAvailableBalance(t, store, account1.ID, 0, 0)
+	requireAvailableBalance(t, store, account2.ID, 100, 100)
+}
+
+func TestCaptureHoldCurrencyMismatch(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		account1 := createFundedAccount(t, store, utils.USD, 100)
+		account2 := createFundedAccount(t, store, utils.EUR, 0)
+
+		placed, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 10, ExpiresAt: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+
+		_, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: placed.Hold.ID, ToAccountID: account2.ID})
+		require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+		// nothing changed, the hold is still active
+		requireAvailableBalance(t, store, account1.ID, 100, 90)
+		hold, err := store.GetHold(ctx, placed.Hold.ID)
+		require.NoError(t, err)
+		require.Equal(t, HoldStatusActive, hold.Status)
+	})
+}
+
+func TestExpireHolds(t *testing.T) {
+	forEachStore(t, testExpireHolds)
+}
+
+func testExpireHolds(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+	now := time.Now()
+
+	soon, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 20, ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+	later, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 30, ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	released, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account1.ID, Amount: 10, ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+	_, err = store.ReleaseHold(ctx, released.Hold.ID)
+	require.NoError(t, err)
+	requireAvailableBalance(t, store, account1.ID, 100, 50)
+
+	// other tests share the SQL database, so only look at the holds of this test
+	_, err = store.ExpireHolds(ctx, now.Add(2*time.Minute))
+	require.NoError(t, err)
+
+	hold, err := store.GetHold(ctx, soon.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, hold.Status)
+	hold, err = store.GetHold(ctx, later.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusActive, hold.Status)
+	hold, err = store.GetHold(ctx, released.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusReleased, hold.Status)
+	requireAvailableBalance(t, store, account1.ID, 100, 70)
+
+	_, err = store.ReleaseHold(ctx, soon.Hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+	_, err = store.CaptureHold(ctx, CaptureHoldParams{HoldID: soon.Hold.ID, ToAccountID: account2.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	hold, err = store.GetHold(ctx, later.Hold.ID)
+	require.NoError(t, err)
+	holds, err := store.ListActiveHoldsByAccount(ctx, account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, []Hold{hold}, holds)
+
+	// a second sweep finds nothing left to expire for this account
+	_, err = store.ExpireHolds(ctx, now.Add(2*time.Minute))
+	require.NoError(t, err)
+	requireAvailableBalance(t, store, account1.ID, 100, 70)
+}
+
+func TestExpireHoldsMemory(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 1000)
+
+	n := expireHoldsBatchSize + 5
+	for i := 0; i < n; i++ {
+		_, err := store.PlaceHold(ctx, PlaceHoldParams{AccountID: account.ID, Amount: 1, ExpiresAt: time.Now().Add(time.Minute)})
+		require.NoError(t, err)
+	}
+
+	// the sweep goes through every batch
+	expired, err := store.ExpireHolds(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, n, expired)
+	requireAvailableBalance(t, store, account.ID, 1000, 1000)
+
+	expired, err = store.ExpireHolds(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, expired)
+}
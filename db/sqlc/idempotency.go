@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// ErrIdempotencyKeyConflict is returned when an idempotency key is replayed with different transfer parameters.
+var ErrIdempotencyKeyConflict = errors.New("idempotency key reused with different parameters")
+
+// replayTransfer looks up the transfer saved under arg.IdempotencyKey for arg.FromAccountID.
+// Keys are scoped to the source account, so one caller can neither see nor block another caller's transfers.
+// It returns found == false if the key has not been used yet.
+func replayTransfer(ctx context.Context, q Querier, arg TransferTxParams) (result TransferTxResult, found bool, err error) {
+	key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		FromAccountID: arg.FromAccountID,
+		Key:           arg.IdempotencyKey,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return result, false, nil
+	}
+	if err != nil {
+		return result, false, err
+	}
+
+	if key.ToAccountID != arg.ToAccountID || key.Amount != int64(arg.Amount) {
+		return result, true, ErrIdempotencyKeyConflict
+	}
+
+	err = json.Unmarshal(key.Result, &result)
+	return result, true, err
+}
+
+func saveIdempotencyKey(ctx context.Context, q Querier, arg TransferTxParams, result TransferTxResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Key:           arg.IdempotencyKey,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        int64(arg.Amount),
+		Result:        data,
+	})
+	return err
+}
+
+// isIdempotencyKeyViolation reports whether err comes from inserting an idempotency key that already exists.
+func isIdempotencyKeyViolation(err error) bool {
+	var constraintErr *ConstraintError
+	return errors.As(err, &constraintErr) && constraintErr.Constraint == "idempotency_keys_pkey"
+}
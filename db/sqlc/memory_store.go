@@ -0,0 +1,1150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"simplebank/currency"
+	"simplebank/fx"
+
+	"github.com/lib/pq"
+)
+
+// memoryState holds every table of the in-memory database.
+type memoryState struct {
+	users           map[string]User
+	accounts        map[int64]Account
+	entries         map[int64]Entry
+	transfers       map[int64]Transfer
+	holds           map[int64]Hold
+	idempotencyKeys map[idempotencyKeyID]IdempotencyKey
+	outbox          map[int64]OutboxEvent
+	auditLog        map[int64]AuditLog
+
+	lastAccountID     int64
+	lastEntryID       int64
+	lastTransferID    int64
+	lastHoldID        int64
+	lastOutboxEventID int64
+	lastAuditLogID    int64
+}
+
+// idempotencyKeyID is the primary key of idempotency_keys.
+type idempotencyKeyID struct {
+	fromAccountID int64
+	key           string
+}
+
+func newMemoryState() *memoryState {
+	return &memoryState{
+		users:           make(map[string]User),
+		accounts:        make(map[int64]Account),
+		entries:         make(map[int64]Entry),
+		transfers:       make(map[int64]Transfer),
+		holds:           make(map[int64]Hold),
+		idempotencyKeys: make(map[idempotencyKeyID]IdempotencyKey),
+		outbox:          make(map[int64]OutboxEvent),
+		auditLog:        make(map[int64]AuditLog),
+	}
+}
+
+// put writes row under key in table, remembering the row it replaces so that a failed transaction can restore it.
+func put[K comparable, V any](q *MemoryQueries, table map[K]V, key K, row V) {
+	remember(q, table, key)
+	table[key] = row
+}
+
+// remove deletes the row under key from table, remembering it so that a failed transaction can restore it.
+func remove[K comparable, V any](q *MemoryQueries, table map[K]V, key K) {
+	remember(q, table, key)
+	delete(table, key)
+}
+
+// remember adds to the undo log of the current transaction, if any, what restores the row under key in table.
+// Inserting into an append-only table such as the outbox or the audit log only costs the delete that undoes it.
+func remember[K comparable, V any](q *MemoryQueries, table map[K]V, key K) {
+	if q.undo == nil {
+		return
+	}
+	row, existed := table[key]
+	*q.undo = append(*q.undo, func() {
+		if existed {
+			table[key] = row
+		} else {
+			delete(table, key)
+		}
+	})
+}
+
+// MemoryQueries implements Querier on top of Go maps, with the same ordering,
+// LIMIT/OFFSET, sql.ErrNoRows and constraint semantics as the SQL queries.
+type MemoryQueries struct {
+	mu    *sync.Mutex
+	state *memoryState
+	// txTime is the start of the current transaction, if any.
+	// Like now() in Postgres, every row written in a transaction gets it as created_at.
+	txTime time.Time
+	// undo is the log of the current transaction, if any: run newest first, it rolls the transaction back.
+	undo *[]func()
+}
+
+var _ Querier = (*MemoryQueries)(nil)
+
+func NewMemoryQueries() *MemoryQueries {
+	return &MemoryQueries{
+		mu:    &sync.Mutex{},
+		state: newMemoryState(),
+	}
+}
+
+// MemoryStore is a Store that keeps all data in memory. Transactions are serialized
+// and write in place, keeping an undo log of the rows they change so that a failed transaction is rolled back.
+// Like Postgres sequences, the ID counters are not rolled back.
+// It is meant for tests and local demos, not for production data volumes.
+type MemoryStore struct {
+	*MemoryQueries
+	txStore
+}
+
+// NewMemoryStore creates an empty in-memory Store
+func NewMemoryStore() Store {
+	return NewMemoryStoreWithRateProvider(nil)
+}
+
+// NewMemoryStoreWithRateProvider creates an empty in-memory Store that prices cross-currency transfers with rates
+func NewMemoryStoreWithRateProvider(rates fx.RateProvider) Store {
+	store := &MemoryStore{
+		MemoryQueries: NewMemoryQueries(),
+	}
+	store.txStore = txStore{execTx: auditTx(store.execTx), rates: rates}
+	return store
+}
+
+// execTx ignores opts: transactions never overlap, so every isolation level is already met,
+// and there are no serialization failures or deadlocks to retry.
+func (store *MemoryStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(Querier) error) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	q := &MemoryQueries{
+		mu:     &sync.Mutex{},
+		state:  store.state,
+		txTime: time.Now(),
+		undo:   &[]func(){},
+	}
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		for i := len(*q.undo) - 1; i >= 0; i-- {
+			(*q.undo)[i]()
+		}
+	}()
+
+	if err := fn(q); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+func (q *MemoryQueries) now() time.Time {
+	if q.txTime.IsZero() {
+		return time.Now()
+	}
+	return q.txTime
+}
+
+func (q *MemoryQueries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	account, ok := q.state.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	balance, err := currency.Add(account.Balance, arg.Amount)
+	if err != nil {
+		return Account{}, outOfRange()
+	}
+	account.Balance = balance
+	account.AvailableBalance = account.Balance - account.HeldBalance
+	put(q, q.state.accounts, account.ID, account)
+	return account, nil
+}
+
+func (q *MemoryQueries) AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	account, ok := q.state.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	held, err := currency.Add(account.HeldBalance, arg.Amount)
+	if err != nil {
+		return Account{}, outOfRange()
+	}
+	account.HeldBalance = held
+	if account.HeldBalance < 0 {
+		return Account{}, checkViolation("accounts", "accounts_held_balance_check")
+	}
+	account.AvailableBalance = account.Balance - account.HeldBalance
+	put(q, q.state.accounts, account.ID, account)
+	return account, nil
+}
+
+func (q *MemoryQueries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfer, ok := q.state.transfers[arg.ID]
+	if !ok {
+		return Transfer{}, sql.ErrNoRows
+	}
+	transfer.ReversedAmount += arg.Amount
+	if transfer.ReversedAmount < 0 || transfer.ReversedAmount > transfer.Amount {
+		return Transfer{}, checkViolation("transfers", "transfers_reversed_amount_check")
+	}
+	put(q, q.state.transfers, transfer.ID, transfer)
+	return transfer, nil
+}
+
+func (q *MemoryQueries) CountPendingTransfersByAccount(ctx context.Context, accountID int64) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var count int64
+	for _, transfer := range q.state.transfers {
+		if transfer.Status == TransferStatusPending && (transfer.FromAccountID == accountID || transfer.ToAccountID == accountID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *MemoryQueries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.state.users[arg.Owner]; !ok {
+		return Account{}, foreignKeyViolation("accounts", "accounts_owner_fkey")
+	}
+	if !currency.IsSupported(arg.Currency) {
+		return Account{}, foreignKeyViolation("accounts", "accounts_currency_fkey")
+	}
+	for _, account := range q.state.accounts {
+		if account.Owner == arg.Owner && account.Currency == arg.Currency && account.Status != AccountStatusClosed {
+			return Account{}, uniqueViolation("owner_currency_key")
+		}
+	}
+
+	q.state.lastAccountID++
+	account := Account{
+		ID:               q.state.lastAccountID,
+		Owner:            arg.Owner,
+		Balance:          arg.Balance,
+		Currency:         arg.Currency,
+		CreatedAt:        q.now(),
+		AvailableBalance: arg.Balance,
+		Status:           AccountStatusActive,
+	}
+	put(q, q.state.accounts, account.ID, account)
+	return account, nil
+}
+
+func (q *MemoryQueries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !arg.Action.Valid() {
+		return AuditLog{}, invalidEnumValue("audit_action", string(arg.Action))
+	}
+	if !json.Valid(arg.Before) || !json.Valid(arg.After) {
+		return AuditLog{}, &pq.Error{Code: InvalidTextRepresentation, Message: "invalid input syntax for type json"}
+	}
+
+	q.state.lastAuditLogID++
+	log := AuditLog{
+		ID:        q.state.lastAuditLogID,
+		Actor:     arg.Actor,
+		Action:    arg.Action,
+		Entity:    arg.Entity,
+		EntityID:  arg.EntityID,
+		Before:    append(json.RawMessage(nil), arg.Before...),
+		After:     append(json.RawMessage(nil), arg.After...),
+		RequestID: arg.RequestID,
+		CreatedAt: q.now(),
+	}
+	put(q, q.state.auditLog, log.ID, log)
+	return log, nil
+}
+
+func (q *MemoryQueries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.state.accounts[arg.AccountID]; !ok {
+		return Entry{}, foreignKeyViolation("entries", "entries_account_id_fkey")
+	}
+	if arg.TransferID.Valid {
+		if _, ok := q.state.transfers[arg.TransferID.Int64]; !ok {
+			return Entry{}, foreignKeyViolation("entries", "entries_transfer_id_fkey")
+		}
+	}
+	if !arg.Type.Valid() {
+		return Entry{}, invalidEnumValue("entry_type", string(arg.Type))
+	}
+
+	q.state.lastEntryID++
+	entry := Entry{
+		ID:          q.state.lastEntryID,
+		AccountID:   arg.AccountID,
+		Amount:      arg.Amount,
+		CreatedAt:   q.now(),
+		TransferID:  arg.TransferID,
+		Type:        arg.Type,
+		ExternalRef: arg.ExternalRef,
+	}
+	put(q, q.state.entries, entry.ID, entry)
+	return entry, nil
+}
+
+func (q *MemoryQueries) CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.state.accounts[arg.AccountID]; !ok {
+		return Hold{}, foreignKeyViolation("holds", "holds_account_id_fkey")
+	}
+	if arg.Amount <= 0 {
+		return Hold{}, checkViolation("holds", "holds_amount_check")
+	}
+
+	q.state.lastHoldID++
+	hold := Hold{
+		ID:        q.state.lastHoldID,
+		AccountID: arg.AccountID,
+		Amount:    arg.Amount,
+		Status:    HoldStatusActive,
+		ExpiresAt: arg.ExpiresAt,
+		CreatedAt: q.now(),
+	}
+	put(q, q.state.holds, hold.ID, hold)
+	return hold, nil
+}
+
+func (q *MemoryQueries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := idempotencyKeyID{fromAccountID: arg.FromAccountID, key: arg.Key}
+	if _, ok := q.state.idempotencyKeys[id]; ok {
+		return IdempotencyKey{}, uniqueViolation("idempotency_keys_pkey")
+	}
+
+	key := IdempotencyKey{
+		Key:           arg.Key,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Result:        append(json.RawMessage(nil), arg.Result...),
+		CreatedAt:     q.now(),
+	}
+	put(q, q.state.idempotencyKeys, id, key)
+	return key, nil
+}
+
+func (q *MemoryQueries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if !arg.EventType.Valid() {
+		return OutboxEvent{}, invalidEnumValue("event_type", string(arg.EventType))
+	}
+	if !json.Valid(arg.Payload) {
+		return OutboxEvent{}, &pq.Error{Code: InvalidTextRepresentation, Message: "invalid input syntax for type json"}
+	}
+
+	q.state.lastOutboxEventID++
+	event := OutboxEvent{
+		ID:          q.state.lastOutboxEventID,
+		EventType:   arg.EventType,
+		AggregateID: arg.AggregateID,
+		Payload:     append(json.RawMessage(nil), arg.Payload...),
+		CreatedAt:   q.now(),
+	}
+	put(q, q.state.outbox, event.ID, event)
+	return event, nil
+}
+
+func (q *MemoryQueries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.state.accounts[arg.FromAccountID]; !ok {
+		return Transfer{}, foreignKeyViolation("transfers", "transfers_from_account_id_fkey")
+	}
+	if _, ok := q.state.accounts[arg.ToAccountID]; !ok {
+		return Transfer{}, foreignKeyViolation("transfers", "transfers_to_account_id_fkey")
+	}
+	if arg.ReversalOf.Valid {
+		if _, ok := q.state.transfers[arg.ReversalOf.Int64]; !ok {
+			return Transfer{}, foreignKeyViolation("transfers", "transfers_reversal_of_fkey")
+		}
+	}
+	if !arg.Status.Valid() {
+		return Transfer{}, invalidEnumValue("transfer_status", string(arg.Status))
+	}
+	if arg.Amount <= 0 {
+		return Transfer{}, checkViolation("transfers", "transfers_amount_check")
+	}
+	if arg.ToAmount.Valid != arg.ExchangeRate.Valid || (arg.ToAmount.Valid && arg.ToAmount.Int64 <= 0) {
+		return Transfer{}, checkViolation("transfers", "transfers_exchange_check")
+	}
+
+	q.state.lastTransferID++
+	transfer := Transfer{
+		ID:            q.state.lastTransferID,
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		CreatedAt:     q.now(),
+		ReversalOf:    arg.ReversalOf,
+		Reason:        arg.Reason,
+		Status:        arg.Status,
+		ToAmount:      arg.ToAmount,
+		ExchangeRate:  arg.ExchangeRate,
+	}
+	put(q, q.state.transfers, transfer.ID, transfer)
+	return transfer, nil
+}
+
+func (q *MemoryQueries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.state.users[arg.Username]; ok {
+		return User{}, uniqueViolation("users_pkey")
+	}
+	for _, user := range q.state.users {
+		if user.Email == arg.Email {
+			return User{}, uniqueViolation("users_email_key")
+		}
+	}
+
+	user := User{
+		Username:          arg.Username,
+		HashedPassword:    arg.HashedPassword,
+		FullName:          arg.FullName,
+		Email:             arg.Email,
+		PasswordChangedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC),
+		CreatedAt:         q.now(),
+	}
+	put(q, q.state.users, user.Username, user)
+	return user, nil
+}
+
+func (q *MemoryQueries) DeleteAccount(ctx context.Context, id int64) (Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	account, ok := q.state.accounts[id]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	for _, entry := range q.state.entries {
+		if entry.AccountID == id {
+			return Account{}, referencedViolation("entries", "entries_account_id_fkey")
+		}
+	}
+	for _, hold := range q.state.holds {
+		if hold.AccountID == id {
+			return Account{}, referencedViolation("holds", "holds_account_id_fkey")
+		}
+	}
+	for _, transfer := range q.state.transfers {
+		if transfer.FromAccountID == id {
+			return Account{}, referencedViolation("transfers", "transfers_from_account_id_fkey")
+		}
+		if transfer.ToAccountID == id {
+			return Account{}, referencedViolation("transfers", "transfers_to_account_id_fkey")
+		}
+	}
+
+	remove(q, q.state.accounts, id)
+	return account, nil
+}
+
+func (q *MemoryQueries) GetAccount(ctx context.Context, id int64) (Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	account, ok := q.state.accounts[id]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	return account, nil
+}
+
+// GetAccountForUpdate behaves like GetAccount: transactions on a MemoryStore are serialized,
+// so the row is already protected for the rest of the transaction.
+func (q *MemoryQueries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	return q.GetAccount(ctx, id)
+}
+
+func (q *MemoryQueries) GetEntriesTotalSince(ctx context.Context, arg GetEntriesTotalSinceParams) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var total int64
+	for _, entry := range q.state.entries {
+		if entry.AccountID == arg.AccountID && !entry.CreatedAt.Before(arg.Since) {
+			total += entry.Amount
+		}
+	}
+	return total, nil
+}
+
+func (q *MemoryQueries) GetEntry(ctx context.Context, id int64) (Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.state.entries[id]
+	if !ok {
+		return Entry{}, sql.ErrNoRows
+	}
+	return entry, nil
+}
+
+func (q *MemoryQueries) GetHold(ctx context.Context, id int64) (Hold, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	hold, ok := q.state.holds[id]
+	if !ok {
+		return Hold{}, sql.ErrNoRows
+	}
+	return hold, nil
+}
+
+func (q *MemoryQueries) GetHoldForUpdate(ctx context.Context, id int64) (Hold, error) {
+	return q.GetHold(ctx, id)
+}
+
+func (q *MemoryQueries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	idempotencyKey, ok := q.state.idempotencyKeys[idempotencyKeyID{fromAccountID: arg.FromAccountID, key: arg.Key}]
+	if !ok {
+		return IdempotencyKey{}, sql.ErrNoRows
+	}
+	return idempotencyKey, nil
+}
+
+func (q *MemoryQueries) GetOutboxEvent(ctx context.Context, id int64) (OutboxEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	event, ok := q.state.outbox[id]
+	if !ok {
+		return OutboxEvent{}, sql.ErrNoRows
+	}
+	return event, nil
+}
+
+func (q *MemoryQueries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfer, ok := q.state.transfers[id]
+	if !ok {
+		return Transfer{}, sql.ErrNoRows
+	}
+	return transfer, nil
+}
+
+// GetTransferForUpdate behaves like GetTransfer, see GetAccountForUpdate.
+func (q *MemoryQueries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	return q.GetTransfer(ctx, id)
+}
+
+func (q *MemoryQueries) GetUser(ctx context.Context, username string) (User, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	user, ok := q.state.users[username]
+	if !ok {
+		return User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (q *MemoryQueries) ListAccountEntryTotals(ctx context.Context, arg ListAccountEntryTotalsParams) ([]ListAccountEntryTotalsRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	accounts := filterByID(q.state.accounts, func(account Account) bool { return account.ID > arg.AfterID })
+	accounts, err := paginate(accounts, arg.BatchSize, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]ListAccountEntryTotalsRow, len(accounts))
+	for i, account := range accounts {
+		rows[i] = ListAccountEntryTotalsRow{ID: account.ID, Balance: account.Balance}
+		for _, entry := range q.state.entries {
+			if entry.AccountID == account.ID {
+				rows[i].EntriesTotal += entry.Amount
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (q *MemoryQueries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := checkAccountStatusFilter(arg.Status); err != nil {
+		return nil, err
+	}
+	accounts := filterByID(q.state.accounts, func(account Account) bool {
+		return hasAccountStatus(account, arg.Status)
+	})
+	return paginate(accounts, arg.Limit, arg.Offset)
+}
+
+func (q *MemoryQueries) ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := checkAccountStatusFilter(arg.Status); err != nil {
+		return nil, err
+	}
+	accounts := filterByID(q.state.accounts, func(account Account) bool {
+		return account.Owner == arg.Owner && hasAccountStatus(account, arg.Status)
+	})
+	return paginate(accounts, arg.Limit, arg.Offset)
+}
+
+func (q *MemoryQueries) ListAccountsByOwnerPage(ctx context.Context, arg ListAccountsByOwnerPageParams) ([]Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := checkAccountStatusFilter(arg.Status); err != nil {
+		return nil, err
+	}
+	accounts := filterByID(q.state.accounts, func(account Account) bool {
+		return account.Owner == arg.Owner && hasAccountStatus(account, arg.Status)
+	})
+	return keysetPage(accounts, accountKey, arg.AfterCreatedAt, arg.AfterID, arg.PageSize)
+}
+
+func (q *MemoryQueries) ListAccountsPage(ctx context.Context, arg ListAccountsPageParams) ([]Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := checkAccountStatusFilter(arg.Status); err != nil {
+		return nil, err
+	}
+	accounts := filterByID(q.state.accounts, func(account Account) bool {
+		return hasAccountStatus(account, arg.Status)
+	})
+	return keysetPage(accounts, accountKey, arg.AfterCreatedAt, arg.AfterID, arg.PageSize)
+}
+
+func (q *MemoryQueries) ListActiveHoldsByAccount(ctx context.Context, accountID int64) ([]Hold, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return filterByID(q.state.holds, func(hold Hold) bool {
+		return hold.AccountID == accountID && hold.Status == HoldStatusActive
+	}), nil
+}
+
+func (q *MemoryQueries) ListAuditLogByEntity(ctx context.Context, arg ListAuditLogByEntityParams) ([]AuditLog, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	logs := filterByID(q.state.auditLog, func(log AuditLog) bool {
+		return log.Entity == arg.Entity && log.EntityID == arg.EntityID
+	})
+	return paginate(logs, arg.Limit, arg.Offset)
+}
+
+func (q *MemoryQueries) ListEntriesByAccount(ctx context.Context, arg ListEntriesByAccountParams) ([]Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := filterByID(q.state.entries, func(entry Entry) bool {
+		return entry.AccountID == arg.AccountID
+	})
+	return paginate(entries, arg.Limit, arg.Offset)
+}
+
+func (q *MemoryQueries) ListEntriesByAccountPage(ctx context.Context, arg ListEntriesByAccountPageParams) ([]Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := filterByID(q.state.entries, func(entry Entry) bool {
+		return entry.AccountID == arg.AccountID
+	})
+	return keysetPage(entries, entryKey, arg.AfterCreatedAt, arg.AfterID, arg.PageSize)
+}
+
+func (q *MemoryQueries) ListEntriesByTransfer(ctx context.Context, transferID int64) ([]Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := filterByID(q.state.entries, func(entry Entry) bool {
+		return entry.TransferID.Valid && entry.TransferID.Int64 == transferID
+	})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Amount < entries[j].Amount })
+	return entries, nil
+}
+
+func (q *MemoryQueries) ListEntryTransferMatches(ctx context.Context, arg ListEntryTransferMatchesParams) ([]ListEntryTransferMatchesRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := filterByID(q.state.entries, func(entry Entry) bool { return entry.ID > arg.AfterID })
+	entries, err := paginate(entries, arg.BatchSize, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]ListEntryTransferMatchesRow, len(entries))
+	for i, entry := range entries {
+		rows[i] = ListEntryTransferMatchesRow{
+			ID:         entry.ID,
+			AccountID:  entry.AccountID,
+			Amount:     entry.Amount,
+			CreatedAt:  entry.CreatedAt,
+			TransferID: entry.TransferID,
+			Type:       entry.Type,
+		}
+		if transfer, ok := q.state.transfers[entry.TransferID.Int64]; ok && isTransferLeg(transfer, entry) {
+			rows[i].MatchingTransfers = 1
+		}
+	}
+	return rows, nil
+}
+
+func (q *MemoryQueries) ListExpiredHoldsForUpdate(ctx context.Context, arg ListExpiredHoldsForUpdateParams) ([]Hold, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	holds := filterByID(q.state.holds, func(hold Hold) bool {
+		return hold.Status == HoldStatusActive && !hold.ExpiresAt.After(arg.ExpiresBefore)
+	})
+	return paginate(holds, arg.BatchSize, 0)
+}
+
+func (q *MemoryQueries) ListOutboxEventsByAggregate(ctx context.Context, arg ListOutboxEventsByAggregateParams) ([]OutboxEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return filterByID(q.state.outbox, func(event OutboxEvent) bool {
+		return event.EventType == arg.EventType && event.AggregateID == arg.AggregateID
+	}), nil
+}
+
+func (q *MemoryQueries) ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := filterByID(q.state.entries, func(entry Entry) bool {
+		return entry.AccountID == arg.AccountID &&
+			!entry.CreatedAt.Before(arg.FromTime) && entry.CreatedAt.Before(arg.ToTime)
+	})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+
+	rows := make([]ListStatementEntriesRow, len(entries))
+	for i, entry := range entries {
+		rows[i] = ListStatementEntriesRow{
+			ID:          entry.ID,
+			AccountID:   entry.AccountID,
+			Amount:      entry.Amount,
+			CreatedAt:   entry.CreatedAt,
+			TransferID:  entry.TransferID,
+			Type:        entry.Type,
+			ExternalRef: entry.ExternalRef,
+		}
+		if transfer, ok := q.state.transfers[entry.TransferID.Int64]; ok {
+			rows[i].FromAccountID = sql.NullInt64{Int64: transfer.FromAccountID, Valid: true}
+			rows[i].ToAccountID = sql.NullInt64{Int64: transfer.ToAccountID, Valid: true}
+		}
+	}
+	return rows, nil
+}
+
+func (q *MemoryQueries) ListTransferEntryMatches(ctx context.Context, arg ListTransferEntryMatchesParams) ([]ListTransferEntryMatchesRow, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfers := filterByID(q.state.transfers, func(transfer Transfer) bool { return transfer.ID > arg.AfterID })
+	transfers, err := paginate(transfers, arg.BatchSize, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]ListTransferEntryMatchesRow, len(transfers))
+	for i, transfer := range transfers {
+		rows[i] = ListTransferEntryMatchesRow{
+			ID:            transfer.ID,
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			Amount:        transfer.Amount,
+			Status:        transfer.Status,
+		}
+		for _, entry := range q.state.entries {
+			if entry.TransferID.Int64 != transfer.ID {
+				continue
+			}
+			if entry.AccountID == transfer.FromAccountID && entry.Amount == -transfer.Amount {
+				rows[i].DebitEntries++
+			}
+			if entry.AccountID == transfer.ToAccountID && entry.Amount == transfer.CreditAmount() {
+				rows[i].CreditEntries++
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (q *MemoryQueries) ListTransferReversals(ctx context.Context, transferID int64) ([]Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return filterByID(q.state.transfers, func(transfer Transfer) bool {
+		return transfer.ReversalOf.Valid && transfer.ReversalOf.Int64 == transferID
+	}), nil
+}
+
+func (q *MemoryQueries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfers := filterByID(q.state.transfers, func(transfer Transfer) bool {
+		return transfer.FromAccountID == arg.FromAccountID || transfer.ToAccountID == arg.ToAccountID
+	})
+	return paginate(transfers, arg.Limit, arg.Offset)
+}
+
+func (q *MemoryQueries) ListTransfersFromAccount(ctx context.Context, arg ListTransfersFromAccountParams) ([]Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfers := filterByID(q.state.transfers, func(transfer Transfer) bool {
+		return transfer.FromAccountID == arg.FromAccountID
+	})
+	return paginate(transfers, arg.Limit, arg.Offset)
+}
+
+func (q *MemoryQueries) ListTransfersFromAccountPage(ctx context.Context, arg ListTransfersFromAccountPageParams) ([]Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfers := filterByID(q.state.transfers, func(transfer Transfer) bool {
+		return transfer.FromAccountID == arg.FromAccountID
+	})
+	return keysetPage(transfers, transferKey, arg.AfterCreatedAt, arg.AfterID, arg.PageSize)
+}
+
+func (q *MemoryQueries) ListTransfersPage(ctx context.Context, arg ListTransfersPageParams) ([]Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfers := filterByID(q.state.transfers, func(transfer Transfer) bool {
+		return transfer.FromAccountID == arg.FromAccountID || transfer.ToAccountID == arg.ToAccountID
+	})
+	return keysetPage(transfers, transferKey, arg.AfterCreatedAt, arg.AfterID, arg.PageSize)
+}
+
+func (q *MemoryQueries) ListTransfersToAccount(ctx context.Context, arg ListTransfersToAccountParams) ([]Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfers := filterByID(q.state.transfers, func(transfer Transfer) bool {
+		return transfer.ToAccountID == arg.ToAccountID
+	})
+	return paginate(transfers, arg.Limit, arg.Offset)
+}
+
+func (q *MemoryQueries) ListTransfersToAccountPage(ctx context.Context, arg ListTransfersToAccountPageParams) ([]Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfers := filterByID(q.state.transfers, func(transfer Transfer) bool {
+		return transfer.ToAccountID == arg.ToAccountID
+	})
+	return keysetPage(transfers, transferKey, arg.AfterCreatedAt, arg.AfterID, arg.PageSize)
+}
+
+func (q *MemoryQueries) ListUndeliveredOutboxEventsForUpdate(ctx context.Context, limit int32) ([]OutboxEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	events := filterByID(q.state.outbox, func(event OutboxEvent) bool {
+		return !event.DeliveredAt.Valid
+	})
+	return paginate(events, limit, 0)
+}
+
+func (q *MemoryQueries) MarkOutboxEventDelivered(ctx context.Context, id int64) (OutboxEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	event, ok := q.state.outbox[id]
+	if !ok {
+		return OutboxEvent{}, sql.ErrNoRows
+	}
+	event.DeliveredAt = sql.NullTime{Time: q.now(), Valid: true}
+	event.Attempts++
+	put(q, q.state.outbox, event.ID, event)
+	return event, nil
+}
+
+func (q *MemoryQueries) MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) (OutboxEvent, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	event, ok := q.state.outbox[arg.ID]
+	if !ok {
+		return OutboxEvent{}, sql.ErrNoRows
+	}
+	event.Attempts++
+	event.LastError = arg.LastError
+	put(q, q.state.outbox, event.ID, event)
+	return event, nil
+}
+
+func (q *MemoryQueries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	account, ok := q.state.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	account.Balance = arg.Balance
+	account.AvailableBalance = account.Balance - account.HeldBalance
+	put(q, q.state.accounts, account.ID, account)
+	return account, nil
+}
+
+func (q *MemoryQueries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	account, ok := q.state.accounts[arg.ID]
+	if !ok {
+		return Account{}, sql.ErrNoRows
+	}
+	if !arg.Status.Valid() {
+		return Account{}, invalidEnumValue("account_status", string(arg.Status))
+	}
+	if arg.Status != AccountStatusClosed {
+		for _, other := range q.state.accounts {
+			if other.ID != account.ID && other.Owner == account.Owner && other.Currency == account.Currency &&
+				other.Status != AccountStatusClosed {
+				return Account{}, uniqueViolation("owner_currency_key")
+			}
+		}
+	}
+	account.Status = arg.Status
+	put(q, q.state.accounts, account.ID, account)
+	return account, nil
+}
+
+func (q *MemoryQueries) UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	hold, ok := q.state.holds[arg.ID]
+	if !ok {
+		return Hold{}, sql.ErrNoRows
+	}
+	if !arg.Status.Valid() {
+		return Hold{}, invalidEnumValue("hold_status", string(arg.Status))
+	}
+	if arg.CapturedAmount < 0 || arg.CapturedAmount > hold.Amount {
+		return Hold{}, checkViolation("holds", "holds_captured_amount_check")
+	}
+	if arg.TransferID.Valid {
+		if _, ok := q.state.transfers[arg.TransferID.Int64]; !ok {
+			return Hold{}, foreignKeyViolation("holds", "holds_transfer_id_fkey")
+		}
+	}
+	hold.Status = arg.Status
+	hold.CapturedAmount = arg.CapturedAmount
+	hold.TransferID = arg.TransferID
+	put(q, q.state.holds, hold.ID, hold)
+	return hold, nil
+}
+
+func (q *MemoryQueries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	transfer, ok := q.state.transfers[arg.ID]
+	if !ok {
+		return Transfer{}, sql.ErrNoRows
+	}
+	if !arg.Status.Valid() {
+		return Transfer{}, invalidEnumValue("transfer_status", string(arg.Status))
+	}
+	transfer.Status = arg.Status
+	put(q, q.state.transfers, transfer.ID, transfer)
+	return transfer, nil
+}
+
+// filterByID returns the rows of table that match keep, ordered by ID.
+func filterByID[T any](table map[int64]T, keep func(T) bool) []T {
+	ids := make([]int64, 0, len(table))
+	for id, row := range table {
+		if keep(row) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := make([]T, len(ids))
+	for i, id := range ids {
+		rows[i] = table[id]
+	}
+	return rows
+}
+
+// isTransferLeg tells whether entry is the debit or the credit of the transfer it is linked to.
+func isTransferLeg(transfer Transfer, entry Entry) bool {
+	return (transfer.FromAccountID == entry.AccountID && transfer.Amount == -entry.Amount) ||
+		(transfer.ToAccountID == entry.AccountID && transfer.CreditAmount() == entry.Amount)
+}
+
+// paginate applies LIMIT and OFFSET the way Postgres does.
+func paginate[T any](rows []T, limit, offset int32) ([]T, error) {
+	if limit < 0 {
+		return nil, &pq.Error{Code: "2201W", Message: "LIMIT must not be negative"}
+	}
+	if offset < 0 {
+		return nil, &pq.Error{Code: "2201X", Message: "OFFSET must not be negative"}
+	}
+	if int(offset) >= len(rows) {
+		return []T{}, nil
+	}
+	rows = rows[offset:]
+	if int(limit) < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// keysetPage returns up to limit rows that come after (afterCreatedAt, afterID) in (created_at, id) order,
+// the way the *Page queries do.
+func keysetPage[T any](rows []T, key func(T) (time.Time, int64), afterCreatedAt time.Time, afterID int64, limit int32) ([]T, error) {
+	if limit < 0 {
+		return nil, &pq.Error{Code: "2201W", Message: "LIMIT must not be negative"}
+	}
+
+	// rows are ordered by id already, so a stable sort keeps id as the tie-breaker
+	sort.SliceStable(rows, func(i, j int) bool {
+		createdAtI, _ := key(rows[i])
+		createdAtJ, _ := key(rows[j])
+		return createdAtI.Before(createdAtJ)
+	})
+
+	page := []T{}
+	for _, row := range rows {
+		if int32(len(page)) == limit {
+			break
+		}
+		createdAt, id := key(row)
+		if createdAt.After(afterCreatedAt) || (createdAt.Equal(afterCreatedAt) && id > afterID) {
+			page = append(page, row)
+		}
+	}
+	return page, nil
+}
+
+func accountKey(account Account) (time.Time, int64) { return account.CreatedAt, account.ID }
+
+func entryKey(entry Entry) (time.Time, int64) { return entry.CreatedAt, entry.ID }
+
+func transferKey(transfer Transfer) (time.Time, int64) { return transfer.CreatedAt, transfer.ID }
+
+// The helpers below build the same errors the SQL store returns for constraint violations.
+
+func uniqueViolation(constraint string) error {
+	return constraintError(&pq.Error{
+		Code:       UniqueViolation,
+		Message:    fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		Constraint: constraint,
+	})
+}
+
+func foreignKeyViolation(table, constraint string) error {
+	return constraintError(&pq.Error{
+		Code:       ForeignKeyViolation,
+		Message:    fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	})
+}
+
+// checkAccountStatusFilter fails like Postgres does when a list query filters on a status that does not exist.
+func checkAccountStatusFilter(status NullAccountStatus) error {
+	if status.Valid && !status.AccountStatus.Valid() {
+		return invalidEnumValue("account_status", string(status.AccountStatus))
+	}
+	return nil
+}
+
+// hasAccountStatus matches the optional status filter of the account list queries.
+func hasAccountStatus(account Account, status NullAccountStatus) bool {
+	return !status.Valid || account.Status == status.AccountStatus
+}
+
+func invalidEnumValue(enum, value string) error {
+	return &pq.Error{
+		Code:    InvalidTextRepresentation,
+		Message: fmt.Sprintf("invalid input value for enum %s: %q", enum, value),
+	}
+}
+
+func outOfRange() error {
+	return constraintError(&pq.Error{
+		Code:    NumericValueOutOfRange,
+		Message: "bigint out of range",
+	})
+}
+
+func checkViolation(table, constraint string) error {
+	return &pq.Error{
+		Code:       CheckViolation,
+		Message:    fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+func referencedViolation(table, constraint string) error {
+	return constraintError(&pq.Error{
+		Code:       ForeignKeyViolation,
+		Message:    fmt.Sprintf("update or delete on table \"accounts\" violates foreign key constraint %q on table %q", constraint, table),
+		Table:      "accounts",
+		Constraint: constraint,
+	})
+}
@@ -5,15 +5,375 @@
 package db
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
+type AccountStatus string
+
+const (
+	AccountStatusActive AccountStatus = "active"
+	AccountStatusFrozen AccountStatus = "frozen"
+	AccountStatusClosed AccountStatus = "closed"
+)
+
+func (e *AccountStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AccountStatus(s)
+	case string:
+		*e = AccountStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AccountStatus: %T", src)
+	}
+	return nil
+}
+
+type NullAccountStatus struct {
+	AccountStatus AccountStatus `json:"account_status"`
+	Valid         bool          `json:"valid"` // Valid is true if AccountStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAccountStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.AccountStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AccountStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAccountStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AccountStatus), nil
+}
+
+func (e AccountStatus) Valid() bool {
+	switch e {
+	case AccountStatusActive,
+		AccountStatusFrozen,
+		AccountStatusClosed:
+		return true
+	}
+	return false
+}
+
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+func (e *AuditAction) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = AuditAction(s)
+	case string:
+		*e = AuditAction(s)
+	default:
+		return fmt.Errorf("unsupported scan type for AuditAction: %T", src)
+	}
+	return nil
+}
+
+type NullAuditAction struct {
+	AuditAction AuditAction `json:"audit_action"`
+	Valid       bool        `json:"valid"` // Valid is true if AuditAction is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullAuditAction) Scan(value interface{}) error {
+	if value == nil {
+		ns.AuditAction, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.AuditAction.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullAuditAction) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.AuditAction), nil
+}
+
+func (e AuditAction) Valid() bool {
+	switch e {
+	case AuditActionCreate,
+		AuditActionUpdate,
+		AuditActionDelete:
+		return true
+	}
+	return false
+}
+
+type EntryType string
+
+const (
+	EntryTypeTransfer   EntryType = "transfer"
+	EntryTypeDeposit    EntryType = "deposit"
+	EntryTypeWithdrawal EntryType = "withdrawal"
+	EntryTypeFee        EntryType = "fee"
+	EntryTypeAdjustment EntryType = "adjustment"
+)
+
+func (e *EntryType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EntryType(s)
+	case string:
+		*e = EntryType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EntryType: %T", src)
+	}
+	return nil
+}
+
+type NullEntryType struct {
+	EntryType EntryType `json:"entry_type"`
+	Valid     bool      `json:"valid"` // Valid is true if EntryType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEntryType) Scan(value interface{}) error {
+	if value == nil {
+		ns.EntryType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EntryType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEntryType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EntryType), nil
+}
+
+func (e EntryType) Valid() bool {
+	switch e {
+	case EntryTypeTransfer,
+		EntryTypeDeposit,
+		EntryTypeWithdrawal,
+		EntryTypeFee,
+		EntryTypeAdjustment:
+		return true
+	}
+	return false
+}
+
+type EventType string
+
+const (
+	EventTypeAccountCreated    EventType = "account_created"
+	EventTypeTransferCompleted EventType = "transfer_completed"
+	EventTypeEntryPosted       EventType = "entry_posted"
+)
+
+func (e *EventType) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = EventType(s)
+	case string:
+		*e = EventType(s)
+	default:
+		return fmt.Errorf("unsupported scan type for EventType: %T", src)
+	}
+	return nil
+}
+
+type NullEventType struct {
+	EventType EventType `json:"event_type"`
+	Valid     bool      `json:"valid"` // Valid is true if EventType is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullEventType) Scan(value interface{}) error {
+	if value == nil {
+		ns.EventType, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.EventType.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullEventType) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.EventType), nil
+}
+
+func (e EventType) Valid() bool {
+	switch e {
+	case EventTypeAccountCreated,
+		EventTypeTransferCompleted,
+		EventTypeEntryPosted:
+		return true
+	}
+	return false
+}
+
+type HoldStatus string
+
+const (
+	HoldStatusActive   HoldStatus = "active"
+	HoldStatusReleased HoldStatus = "released"
+	HoldStatusCaptured HoldStatus = "captured"
+	HoldStatusExpired  HoldStatus = "expired"
+)
+
+func (e *HoldStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = HoldStatus(s)
+	case string:
+		*e = HoldStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for HoldStatus: %T", src)
+	}
+	return nil
+}
+
+type NullHoldStatus struct {
+	HoldStatus HoldStatus `json:"hold_status"`
+	Valid      bool       `json:"valid"` // Valid is true if HoldStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullHoldStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.HoldStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.HoldStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullHoldStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.HoldStatus), nil
+}
+
+func (e HoldStatus) Valid() bool {
+	switch e {
+	case HoldStatusActive,
+		HoldStatusReleased,
+		HoldStatusCaptured,
+		HoldStatusExpired:
+		return true
+	}
+	return false
+}
+
+type TransferStatus string
+
+const (
+	TransferStatusPending TransferStatus = "pending"
+	TransferStatusPosted  TransferStatus = "posted"
+	TransferStatusFailed  TransferStatus = "failed"
+)
+
+func (e *TransferStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TransferStatus(s)
+	case string:
+		*e = TransferStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TransferStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTransferStatus struct {
+	TransferStatus TransferStatus `json:"transfer_status"`
+	Valid          bool           `json:"valid"` // Valid is true if TransferStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTransferStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TransferStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TransferStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTransferStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TransferStatus), nil
+}
+
+func (e TransferStatus) Valid() bool {
+	switch e {
+	case TransferStatusPending,
+		TransferStatusPosted,
+		TransferStatusFailed:
+		return true
+	}
+	return false
+}
+
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// ledger balance: the sum of the posted entries
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// part of the balance reserved by pending transfers and active holds
+	HeldBalance int64 `json:"held_balance"`
+	// part of the balance that can be spent
+	AvailableBalance int64 `json:"available_balance"`
+	// frozen and closed accounts cannot move money; closed is final
+	Status AccountStatus `json:"status"`
+}
+
+// append-only record of every change made through the store
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// user who asked for the change, if known
+	Actor  sql.NullString `json:"actor"`
+	Action AuditAction    `json:"action"`
+	// kind of row changed: account, entry, hold, transfer or user
+	Entity   string `json:"entity"`
+	EntityID string `json:"entity_id"`
+	// the row before the change, the JSON value null for a creation
+	Before json.RawMessage `json:"before"`
+	// the row after the change, the JSON value null for a deletion
+	After json.RawMessage `json:"after"`
+	// request the change was made for, to correlate with the logs
+	RequestID sql.NullString `json:"request_id"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// supported ISO 4217 currencies, kept in sync with the currency package
+type Currency struct {
+	Code string `json:"code"`
+	// number of decimals of the minor unit
+	Exponent int16 `json:"exponent"`
 }
 
 type Entry struct {
@@ -22,13 +382,77 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer this entry is a leg of
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Type       EntryType     `json:"type"`
+	// reference of the deposit or withdrawal in the external system
+	ExternalRef sql.NullString `json:"external_ref"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// amount reserved on the account while the hold is active
+	Amount         int64      `json:"amount"`
+	CapturedAmount int64      `json:"captured_amount"`
+	Status         HoldStatus `json:"status"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// transfer that captured the hold
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type IdempotencyKey struct {
+	Key           string `json:"key"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// serialized TransferTxResult returned on replay
+	Result    json.RawMessage `json:"result"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ledger events written in the transaction that caused them, waiting to be published
+type OutboxEvent struct {
+	ID        int64     `json:"id"`
+	EventType EventType `json:"event_type"`
+	// id of the account, transfer or entry the event is about
+	AggregateID int64 `json:"aggregate_id"`
+	// the account, transfer or entry as JSON
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	// number of times the event was handed to the publisher
+	Attempts int32 `json:"attempts"`
+	// why the last delivery failed
+	LastError   sql.NullString `json:"last_error"`
+	DeliveredAt sql.NullTime   `json:"delivered_at"`
 }
 
 type Transfer struct {
 	ID            int64 `json:"id"`
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
-	// must be positive
+	// must be positive, in the currency of the source account
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer this transfer reverses
+	ReversalOf sql.NullInt64 `json:"reversal_of"`
+	// why the transfer was reversed
+	Reason sql.NullString `json:"reason"`
+	// part of the amount already sent back by reversals
+	ReversedAmount int64          `json:"reversed_amount"`
+	Status         TransferStatus `json:"status"`
+	// amount credited in the currency of the destination account, when it differs from the source currency
+	ToAmount sql.NullInt64 `json:"to_amount"`
+	// units of the destination currency bought by one unit of the source currency
+	ExchangeRate sql.NullString `json:"exchange_rate"`
+}
+
+type User struct {
+	Username          string    `json:"username"`
+	HashedPassword    string    `json:"hashed_password"`
+	FullName          string    `json:"full_name"`
+	Email             string    `json:"email"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
 }
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownEventType is returned when decoding an outbox event of a type this version does not know.
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// DeliverFunc hands one outbox event to the outside world.
+// It must be safe to call again with the same event: delivery is at least once.
+type DeliverFunc func(ctx context.Context, event OutboxEvent) error
+
+// CreateAccountTx creates an account and records an AccountCreated event within a single database transaction.
+func (store txStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return writeEvent(ctx, q, EventTypeAccountCreated, account.ID, account)
+	})
+
+	return account, err
+}
+
+// DeliverOutboxEvents locks up to limit undelivered events, oldest first, and hands them to deliver in order.
+// Delivered events are marked so, and are not handed out again.
+// The first failure is recorded on its event and stops the batch, so that later events of the batch
+// do not overtake it; the failure is returned once the events delivered before it are marked.
+// Events locked by a concurrent call are skipped, so several relays can share the outbox.
+//
+// The events stay locked while deliver runs. If the transaction fails to commit after deliver succeeded,
+// the events are delivered again by a later call.
+func (store txStore) DeliverOutboxEvents(ctx context.Context, limit int32, deliver DeliverFunc) (int, error) {
+	var delivered int
+	var deliverErr error
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		delivered, deliverErr = 0, nil
+
+		events, err := q.ListUndeliveredOutboxEventsForUpdate(ctx, limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if deliverErr = deliver(ctx, event); deliverErr != nil {
+				_, err := q.MarkOutboxEventFailed(ctx, MarkOutboxEventFailedParams{
+					ID:        event.ID,
+					LastError: sql.NullString{String: deliverErr.Error(), Valid: true},
+				})
+				deliverErr = fmt.Errorf("event %d: %w", event.ID, deliverErr)
+				return err
+			}
+
+			if _, err := q.MarkOutboxEventDelivered(ctx, event.ID); err != nil {
+				return err
+			}
+			delivered++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return delivered, deliverErr
+}
+
+// Decode returns the payload of the event as the row it is about:
+// an Account for AccountCreated, a Transfer for TransferCompleted and an Entry for EntryPosted.
+func (event OutboxEvent) Decode() (any, error) {
+	switch event.EventType {
+	case EventTypeAccountCreated:
+		return decodePayload[Account](event)
+	case EventTypeTransferCompleted:
+		return decodePayload[Transfer](event)
+	case EventTypeEntryPosted:
+		return decodePayload[Entry](event)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, event.EventType)
+}
+
+func decodePayload[T any](event OutboxEvent) (T, error) {
+	var payload T
+	err := json.Unmarshal(event.Payload, &payload)
+	return payload, err
+}
+
+// writeEvent records an event about the row payload in the outbox, inside the caller's transaction,
+// so that the event is published if and only if the transaction commits.
+func writeEvent(ctx context.Context, q Querier, eventType EventType, aggregateID int64, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+	})
+	return err
+}
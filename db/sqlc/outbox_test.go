@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"simplebank/db/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAccountTxOutbox(t *testing.T) {
+	forEachStore(t, testCreateAccountTxOutbox)
+}
+
+func testCreateAccountTxOutbox(t *testing.T, store Store) {
+	ctx := context.Background()
+	arg := CreateAccountParams{
+		Owner:    createRandomUserIn(t, store).Username,
+		Currency: utils.RandomCurrency(),
+	}
+
+	account, err := store.CreateAccountTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Owner, account.Owner)
+
+	events := requireEvents(t, store, EventTypeAccountCreated, account.ID, 1)
+	payload, err := events[0].Decode()
+	require.NoError(t, err)
+	require.Equal(t, account.ID, payload.(Account).ID)
+	require.Equal(t, account.Owner, payload.(Account).Owner)
+
+	_, err = store.CreateAccountTx(ctx, arg)
+	require.ErrorIs(t, err, ErrUniqueViolation)
+}
+
+func TestTransferTxOutbox(t *testing.T) {
+	forEachStore(t, testTransferTxOutbox)
+}
+
+func testTransferTxOutbox(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	arg := TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         10,
+		IdempotencyKey: utils.RandomString(16),
+	}
+	result, err := store.TransferTx(ctx, arg)
+	require.NoError(t, err)
+
+	events := requireEvents(t, store, EventTypeTransferCompleted, result.Transfer.ID, 1)
+	payload, err := events[0].Decode()
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, payload.(Transfer).ID)
+	require.Equal(t, result.Transfer.Amount, payload.(Transfer).Amount)
+	require.Equal(t, TransferStatusPosted, payload.(Transfer).Status)
+
+	for _, entry := range []Entry{result.FromEntry, result.ToEntry} {
+		entryEvents := requireEvents(t, store, EventTypeEntryPosted, entry.ID, 1)
+		require.Less(t, entryEvents[0].ID, events[0].ID)
+
+		payload, err := entryEvents[0].Decode()
+		require.NoError(t, err)
+		require.Equal(t, entry.Amount, payload.(Entry).Amount)
+	}
+
+	// a replay does not move money again, so there is nothing new to tell
+	_, err = store.TransferTx(ctx, arg)
+	require.NoError(t, err)
+	requireEvents(t, store, EventTypeTransferCompleted, result.Transfer.ID, 1)
+
+	deposit, err := store.DepositTx(ctx, DepositTxParams{AccountID: account2.ID, Amount: 5})
+	require.NoError(t, err)
+	requireEvents(t, store, EventTypeEntryPosted, deposit.Entry.ID, 1)
+}
+
+func TestDeliverOutboxEvents(t *testing.T) {
+	forEachStore(t, testDeliverOutboxEvents)
+}
+
+func testDeliverOutboxEvents(t *testing.T, store Store) {
+	ctx := context.Background()
+	deliverAll := func(ctx context.Context, event OutboxEvent) error { return nil }
+
+	// start from an empty outbox
+	for {
+		n, err := store.DeliverOutboxEvents(ctx, 100, deliverAll)
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 0)
+	var ids []int64
+	for i := 0; i < 3; i++ {
+		result, err := store.DepositTx(ctx, DepositTxParams{AccountID: account.ID, Amount: 10})
+		require.NoError(t, err)
+		ids = append(ids, requireEvents(t, store, EventTypeEntryPosted, result.Entry.ID, 1)[0].ID)
+	}
+
+	// the first failure stops the batch
+	errBroker := errors.New("broker unavailable")
+	var delivered []int64
+	n, err := store.DeliverOutboxEvents(ctx, 100, func(ctx context.Context, event OutboxEvent) error {
+		if event.ID == ids[1] {
+			return errBroker
+		}
+		delivered = append(delivered, event.ID)
+		return nil
+	})
+	require.ErrorIs(t, err, errBroker)
+	require.Equal(t, 1, n)
+	require.Equal(t, ids[:1], delivered)
+
+	event, err := store.GetOutboxEvent(ctx, ids[0])
+	require.NoError(t, err)
+	require.True(t, event.DeliveredAt.Valid)
+	require.Equal(t, int32(1), event.Attempts)
+
+	event, err = store.GetOutboxEvent(ctx, ids[1])
+	require.NoError(t, err)
+	require.False(t, event.DeliveredAt.Valid)
+	require.Equal(t, int32(1), event.Attempts)
+	require.Equal(t, errBroker.Error(), event.LastError.String)
+
+	event, err = store.GetOutboxEvent(ctx, ids[2])
+	require.NoError(t, err)
+	require.False(t, event.DeliveredAt.Valid)
+	require.Zero(t, event.Attempts)
+
+	// the next call retries from the failed event, in order, one batch at a time
+	delivered = nil
+	record := func(ctx context.Context, event OutboxEvent) error {
+		delivered = append(delivered, event.ID)
+		return nil
+	}
+	n, err = store.DeliverOutboxEvents(ctx, 1, record)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	n, err = store.DeliverOutboxEvents(ctx, 100, record)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, ids[1:], delivered)
+
+	event, err = store.GetOutboxEvent(ctx, ids[1])
+	require.NoError(t, err)
+	require.True(t, event.DeliveredAt.Valid)
+	require.Equal(t, int32(2), event.Attempts)
+
+	n, err = store.DeliverOutboxEvents(ctx, 100, record)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestOutboxEventDecode(t *testing.T) {
+	_, err := OutboxEvent{EventType: "account_deleted", Payload: []byte(`{}`)}.Decode()
+	require.ErrorIs(t, err, ErrUnknownEventType)
+
+	_, err = OutboxEvent{EventType: EventTypeEntryPosted, Payload: []byte(`[]`)}.Decode()
+	require.Error(t, err)
+}
+
+func requireEvents(t *testing.T, q Querier, eventType EventType, aggregateID int64, n int) []OutboxEvent {
+	events, err := q.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		EventType:   eventType,
+		AggregateID: aggregateID,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, n)
+	for _, event := range events {
+		require.Equal(t, eventType, event.EventType)
+		require.Equal(t, aggregateID, event.AggregateID)
+		require.False(t, event.CreatedAt.IsZero())
+	}
+	return events
+}
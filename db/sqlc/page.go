@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidPageToken is returned when a page token was not issued by this package.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// ErrInvalidPageSize is returned when a page size is not positive.
+var ErrInvalidPageSize = errors.New("invalid page size")
+
+// PageCursor is the position of a row in (created_at, id) order.
+// The zero cursor is the position before the first row.
+type PageCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+// Token encodes the cursor as an opaque string that is safe to put in a URL.
+func (cursor PageCursor) Token() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParsePageToken decodes a token returned by PageCursor.Token.
+// An empty token is the zero cursor, so the first page is read with an empty token.
+func ParsePageToken(token string) (PageCursor, error) {
+	var cursor PageCursor
+	if token == "" {
+		return cursor, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: %v", ErrInvalidPageToken, err)
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("%w: %v", ErrInvalidPageToken, err)
+	}
+	return cursor, nil
+}
+
+// PageRequest asks for PageSize rows after the row PageToken points at.
+type PageRequest struct {
+	PageToken string `json:"page_token"`
+	PageSize  int32  `json:"page_size"`
+}
+
+// Page holds the rows of a page and the token of the next one.
+// NextPageToken is empty on the last page.
+type Page[T any] struct {
+	Items         []T    `json:"items"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+}
+
+// fetchPage decodes the request and calls list with one row more than asked for,
+// which tells whether there is a next page without another query.
+func fetchPage[T any](req PageRequest, key func(T) PageCursor, list func(after PageCursor, limit int32) ([]T, error)) (Page[T], error) {
+	var page Page[T]
+	if req.PageSize < 1 || req.PageSize == math.MaxInt32 {
+		return page, fmt.Errorf("%w: %d", ErrInvalidPageSize, req.PageSize)
+	}
+
+	after, err := ParsePageToken(req.PageToken)
+	if err != nil {
+		return page, err
+	}
+
+	items, err := list(after, req.PageSize+1)
+	if err != nil {
+		return page, err
+	}
+
+	if int32(len(items)) > req.PageSize {
+		items = items[:req.PageSize]
+		page.NextPageToken = key(items[len(items)-1]).Token()
+	}
+	page.Items = items
+	return page, nil
+}
+
+func accountCursor(account Account) PageCursor {
+	return PageCursor{CreatedAt: account.CreatedAt, ID: account.ID}
+}
+
+func entryCursor(entry Entry) PageCursor {
+	return PageCursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
+
+func transferCursor(transfer Transfer) PageCursor {
+	return PageCursor{CreatedAt: transfer.CreatedAt, ID: transfer.ID}
+}
+
+// PageAccounts returns a page of the accounts with status in creation order,
+// or of all accounts if status is not valid, like ListAccounts.
+func PageAccounts(ctx context.Context, q Querier, status NullAccountStatus, req PageRequest) (Page[Account], error) {
+	return fetchPage(req, accountCursor, func(after PageCursor, limit int32) ([]Account, error) {
+		return q.ListAccountsPage(ctx, ListAccountsPageParams{
+			Status:         status,
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			PageSize:       limit,
+		})
+	})
+}
+
+// PageAccountsByOwner returns a page of the accounts of owner in creation order,
+// filtered by status like ListAccountsByOwner.
+func PageAccountsByOwner(ctx context.Context, q Querier, owner string, status NullAccountStatus, req PageRequest) (Page[Account], error) {
+	return fetchPage(req, accountCursor, func(after PageCursor, limit int32) ([]Account, error) {
+		return q.ListAccountsByOwnerPage(ctx, ListAccountsByOwnerPageParams{
+			Owner:          owner,
+			Status:         status,
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			PageSize:       limit,
+		})
+	})
+}
+
+// PageEntriesByAccount returns a page of the entries of an account in creation order.
+func PageEntriesByAccount(ctx context.Context, q Querier, accountID int64, req PageRequest) (Page[Entry], error) {
+	return fetchPage(req, entryCursor, func(after PageCursor, limit int32) ([]Entry, error) {
+		return q.ListEntriesByAccountPage(ctx, ListEntriesByAccountPageParams{
+			AccountID:      accountID,
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			PageSize:       limit,
+		})
+	})
+}
+
+// PageTransfersFromAccount returns a page of the transfers sent by an account in creation order.
+func PageTransfersFromAccount(ctx context.Context, q Querier, accountID int64, req PageRequest) (Page[Transfer], error) {
+	return fetchPage(req, transferCursor, func(after PageCursor, limit int32) ([]Transfer, error) {
+		return q.ListTransfersFromAccountPage(ctx, ListTransfersFromAccountPageParams{
+			FromAccountID:  accountID,
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			PageSize:       limit,
+		})
+	})
+}
+
+// PageTransfersToAccount returns a page of the transfers received by an account in creation order.
+func PageTransfersToAccount(ctx context.Context, q Querier, accountID int64, req PageRequest) (Page[Transfer], error) {
+	return fetchPage(req, transferCursor, func(after PageCursor, limit int32) ([]Transfer, error) {
+		return q.ListTransfersToAccountPage(ctx, ListTransfersToAccountPageParams{
+			ToAccountID:    accountID,
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			PageSize:       limit,
+		})
+	})
+}
+
+// PageTransfers returns a page of the transfers sent by fromAccountID or received by toAccountID in creation order,
+// like ListTransfers.
+func PageTransfers(ctx context.Context, q Querier, fromAccountID, toAccountID int64, req PageRequest) (Page[Transfer], error) {
+	return fetchPage(req, transferCursor, func(after PageCursor, limit int32) ([]Transfer, error) {
+		return q.ListTransfersPage(ctx, ListTransfersPageParams{
+			FromAccountID:  fromAccountID,
+			ToAccountID:    toAccountID,
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			PageSize:       limit,
+		})
+	})
+}
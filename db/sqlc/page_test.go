@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"simplebank/db/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageToken(t *testing.T) {
+	cursor := PageCursor{CreatedAt: time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC), ID: 42}
+
+	got, err := ParsePageToken(cursor.Token())
+	require.NoError(t, err)
+	require.True(t, cursor.CreatedAt.Equal(got.CreatedAt))
+	require.Equal(t, cursor.ID, got.ID)
+
+	got, err = ParsePageToken("")
+	require.NoError(t, err)
+	require.Zero(t, got)
+
+	for _, token := range []string{"not base64!", "bm90IGpzb24"} {
+		_, err = ParsePageToken(token)
+		require.ErrorIs(t, err, ErrInvalidPageToken)
+	}
+}
+
+func TestPageEntriesByAccount(t *testing.T) {
+	forEachStore(t, testPageEntriesByAccount)
+}
+
+func testPageEntriesByAccount(t *testing.T, store Store) {
+	ctx := context.Background()
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 0)
+
+	var want []int64
+	for i := 0; i < 7; i++ {
+		entry, err := store.CreateEntry(ctx, CreateEntryParams{AccountID: account.ID, Amount: utils.RandomMoney(), Type: EntryTypeAdjustment})
+		require.NoError(t, err)
+		want = append(want, entry.ID)
+	}
+
+	var got []int64
+	req := PageRequest{PageSize: 3}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 10)
+
+		page, err := PageEntriesByAccount(ctx, store, account.ID, req)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page.Items), 3)
+		for _, entry := range page.Items {
+			got = append(got, entry.ID)
+		}
+
+		// rows written between pages show up once, at the end
+		if pages == 0 {
+			entry, err := store.CreateEntry(ctx, CreateEntryParams{AccountID: account.ID, Amount: utils.RandomMoney(), Type: EntryTypeAdjustment})
+			require.NoError(t, err)
+			want = append(want, entry.ID)
+		}
+
+		if page.NextPageToken == "" {
+			break
+		}
+		req.PageToken = page.NextPageToken
+	}
+	require.Equal(t, want, got)
+}
+
+func TestPageTransfers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		currency := utils.RandomCurrency()
+		account1 := createFundedAccount(t, store, currency, 1000)
+		account2 := createFundedAccount(t, store, currency, 1000)
+
+		var want []int64
+		for i := 0; i < 4; i++ {
+			result, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+			require.NoError(t, err)
+			want = append(want, result.Transfer.ID)
+		}
+
+		page, err := PageTransfersFromAccount(ctx, store, account1.ID, PageRequest{PageSize: 4})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 4)
+		require.Empty(t, page.NextPageToken)
+
+		page, err = PageTransfersToAccount(ctx, store, account2.ID, PageRequest{PageSize: 2})
+		require.NoError(t, err)
+		require.Equal(t, want[:2], []int64{page.Items[0].ID, page.Items[1].ID})
+		require.NotEmpty(t, page.NextPageToken)
+
+		page, err = PageTransfers(ctx, store, account1.ID, account1.ID, PageRequest{PageToken: page.NextPageToken, PageSize: 2})
+		require.NoError(t, err)
+		require.Equal(t, want[2:], []int64{page.Items[0].ID, page.Items[1].ID})
+		require.Empty(t, page.NextPageToken)
+	})
+}
+
+func TestPageAccountsByOwner(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		owner := createRandomUserIn(t, store).Username
+
+		var want []int64
+		for _, currency := range []string{utils.USD, utils.EUR, utils.CAD} {
+			account, err := store.CreateAccount(ctx, CreateAccountParams{Owner: owner, Currency: currency})
+			require.NoError(t, err)
+			want = append(want, account.ID)
+		}
+
+		var got []int64
+		req := PageRequest{PageSize: 1}
+		for {
+			page, err := PageAccountsByOwner(ctx, store, owner, NullAccountStatus{}, req)
+			require.NoError(t, err)
+			for _, account := range page.Items {
+				got = append(got, account.ID)
+			}
+			if page.NextPageToken == "" {
+				break
+			}
+			req.PageToken = page.NextPageToken
+		}
+		require.Equal(t, want, got)
+
+		page, err := PageAccounts(ctx, store, NullAccountStatus{}, PageRequest{PageSize: 1})
+		require.NoError(t, err)
+		require.Len(t, page.Items, 1)
+	})
+}
+
+func TestPageAccountsByStatus(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		owner := createRandomUserIn(t, store).Username
+
+		var frozen []int64
+		for i, currency := range []string{utils.USD, utils.EUR, utils.CAD} {
+			account, err := store.CreateAccount(ctx, CreateAccountParams{Owner: owner, Currency: currency})
+			require.NoError(t, err)
+			if i == 1 {
+				continue
+			}
+			_, err = store.UpdateAccountStatus(ctx, UpdateAccountStatusParams{ID: account.ID, Status: AccountStatusFrozen})
+			require.NoError(t, err)
+			frozen = append(frozen, account.ID)
+		}
+		status := NullAccountStatus{AccountStatus: AccountStatusFrozen, Valid: true}
+
+		page, err := PageAccountsByOwner(ctx, store, owner, status, PageRequest{PageSize: 10})
+		require.NoError(t, err)
+		var got []int64
+		for _, account := range page.Items {
+			got = append(got, account.ID)
+		}
+		require.Equal(t, frozen, got)
+
+		// PageAccounts applies the same filter as ListAccounts
+		var want []Account
+		for offset := int32(0); ; offset += 100 {
+			accounts, err := store.ListAccounts(ctx, ListAccountsParams{Status: status, Limit: 100, Offset: offset})
+			require.NoError(t, err)
+			want = append(want, accounts...)
+			if len(accounts) < 100 {
+				break
+			}
+		}
+
+		var all []Account
+		req := PageRequest{PageSize: 100}
+		for {
+			page, err := PageAccounts(ctx, store, status, req)
+			require.NoError(t, err)
+			all = append(all, page.Items...)
+			if page.NextPageToken == "" {
+				break
+			}
+			req.PageToken = page.NextPageToken
+		}
+		require.ElementsMatch(t, want, all)
+	})
+}
+
+func TestPageErrors(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		_, err := PageAccounts(ctx, store, NullAccountStatus{}, PageRequest{PageSize: 0})
+		require.ErrorIs(t, err, ErrInvalidPageSize)
+
+		_, err = PageAccounts(ctx, store, NullAccountStatus{}, PageRequest{PageToken: "%%%", PageSize: 10})
+		require.ErrorIs(t, err, ErrInvalidPageToken)
+	})
+}
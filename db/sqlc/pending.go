@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTransferTransition is returned when a transfer is asked to move to a status it cannot reach from its current one.
+var ErrInvalidTransferTransition = errors.New("invalid transfer status transition")
+
+// transferTransitions lists the statuses each status can move to.
+// Posted and failed are final.
+var transferTransitions = map[TransferStatus][]TransferStatus{
+	TransferStatusPending: {TransferStatusPosted, TransferStatusFailed},
+}
+
+// checkTransition makes sure that transfer can move to status.
+func checkTransition(transfer Transfer, status TransferStatus) error {
+	for _, next := range transferTransitions[transfer.Status] {
+		if next == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: transfer %d is %s, cannot become %s",
+		ErrInvalidTransferTransition, transfer.ID, transfer.Status, status)
+}
+
+type CreatePendingTransferTxParams struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        Amount `json:"amount"`
+}
+
+type PendingTransferTxResult struct {
+	Transfer    Transfer `json:"transfer"`
+	FromAccount Account  `json:"from_account"`
+}
+
+// CreatePendingTransferTx records a transfer that has not moved money yet.
+// It locks both accounts and validates them like TransferTx does, then creates a pending transfer
+// and holds its amount on the source account, so that the funds are no longer available but the ledger balance is unchanged.
+// The transfer is settled later with PostTransferTx or cancelled with VoidTransferTx.
+func (store txStore) CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParams) (PendingTransferTxResult, error) {
+	var result PendingTransferTxResult
+	if err := arg.Amount.Validate(); err != nil {
+		return result, fmt.Errorf("transfer: %w", err)
+	}
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		transferArg := CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        int64(arg.Amount),
+			Status:        TransferStatusPending,
+		}
+		if err := checkTransfer(fromAccount, toAccount, transferArg); err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, transferArg)
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: int64(arg.Amount),
+			ID:     arg.FromAccountID,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// PostTransferTx settles a pending transfer.
+// It locks the transfer and both accounts, releases the hold on the source account,
+// then writes the entries and balances like TransferTx does and marks the transfer posted.
+// It fails with ErrInvalidTransferTransition unless the transfer is pending,
+// and with ErrAccountFrozen or ErrAccountClosed unless both accounts are active.
+func (store txStore) PostTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		transfer, err := q.GetTransferForUpdate(ctx, transferID)
+		if err != nil {
+			return err
+		}
+		if err := checkTransition(transfer, TransferStatusPosted); err != nil {
+			return err
+		}
+
+		fromAccount, toAccount, err := lockAccounts(ctx, q, transfer.FromAccountID, transfer.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkAccountsActive(fromAccount, toAccount); err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: -transfer.Amount,
+			ID:     transfer.FromAccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			Status: TransferStatusPosted,
+			ID:     transfer.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return postTransfer(ctx, q, &result)
+	})
+
+	return result, err
+}
+
+// VoidTransferTx cancels a pending transfer.
+// It locks the transfer, releases the hold on the source account and marks the transfer failed.
+// It fails with ErrInvalidTransferTransition unless the transfer is pending.
+func (store txStore) VoidTransferTx(ctx context.Context, transferID int64) (PendingTransferTxResult, error) {
+	var result PendingTransferTxResult
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		transfer, err := q.GetTransferForUpdate(ctx, transferID)
+		if err != nil {
+			return err
+		}
+		if err := checkTransition(transfer, TransferStatusFailed); err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			Amount: -transfer.Amount,
+			ID:     transfer.FromAccountID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+			Status: TransferStatusFailed,
+			ID:     transfer.ID,
+		})
+		return err
+	})
+
+	return result, err
+}
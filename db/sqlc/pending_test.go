@@ -0,0 +1,261 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simplebank/db/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireAvailableBalance(t *testing.T, q Querier, accountID int64, balance, available int64) {
+	account, err := q.GetAccount(context.Background(), accountID)
+	require.NoError(t, err)
+	require.Equal(t, balance, account.Balance)
+	require.Equal(t, available, account.AvailableBalance)
+	require.Equal(t, balance-available, account.HeldBalance)
+}
+
+func TestPostTransferTx(t *testing.T) {
+	forEachStore(t, testPostTransferTx)
+}
+
+func testPostTransferTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	pending, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, pending.Transfer.Status)
+	require.Equal(t, int64(30), pending.FromAccount.HeldBalance)
+	require.Equal(t, int64(70), pending.FromAccount.AvailableBalance)
+
+	// the money is held but has not moved
+	requireAvailableBalance(t, store, account1.ID, 100, 70)
+	requireAvailableBalance(t, store, account2.ID, 0, 0)
+	entries, err := store.ListEntriesByTransfer(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	result, err := store.PostTransferTx(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, pending.Transfer.ID, result.Transfer.ID)
+	require.Equal(t, TransferStatusPosted, result.Transfer.Status)
+	require.Equal(t, int64(-30), result.FromEntry.Amount)
+	require.Equal(t, int64(30), result.ToEntry.Amount)
+	require.Equal(t, sql.NullInt64{Int64: pending.Transfer.ID, Valid: true}, result.FromEntry.TransferID)
+	require.Equal(t, int64(70), result.FromAccount.AvailableBalance)
+	require.Equal(t, int64(30), result.ToAccount.AvailableBalance)
+
+	requireAvailableBalance(t, store, account1.ID, 70, 70)
+	requireAvailableBalance(t, store, account2.ID, 30, 30)
+}
+
+func TestVoidTransferTx(t *testing.T) {
+	forEachStore(t, testVoidTransferTx)
+}
+
+func testVoidTransferTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	pending, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+
+	result, err := store.VoidTransferTx(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusFailed, result.Transfer.Status)
+	require.Equal(t, int64(100), result.FromAccount.AvailableBalance)
+
+	requireAvailableBalance(t, store, account1.ID, 100, 100)
+	requireAvailableBalance(t, store, account2.ID, 0, 0)
+	entries, err := store.ListEntriesByTransfer(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestCreatePendingTransferTxErrors(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		account1 := createFundedAccount(t, store, utils.USD, 100)
+		account2 := createFundedAccount(t, store, utils.USD, 0)
+		account3 := createFundedAccount(t, store, utils.EUR, 0)
+
+		_, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account3.ID, Amount: 10})
+		require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+		_, err = store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60})
+		require.NoError(t, err)
+
+		// held funds cannot be held twice
+		_, err = store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 60})
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+		requireAvailableBalance(t, store, account1.ID, 100, 40)
+
+		_, err = store.PostTransferTx(ctx, -1)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.VoidTransferTx(ctx, -1)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestHeldFundsAreNotAvailable(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		currency := utils.RandomCurrency()
+		account1 := createFundedAccount(t, store, currency, 100)
+		account2 := createFundedAccount(t, store, currency, 0)
+
+		_, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 80})
+		require.NoError(t, err)
+
+		_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+		_, err = store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account1.ID, Amount: 30})
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+
+		_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 20})
+		require.NoError(t, err)
+		requireAvailableBalance(t, store, account1.ID, 80, 0)
+	})
+}
+
+func TestIllegalTransferTransitions(t *testing.T) {
+	forEachStore(t, testIllegalTransferTransitions)
+}
+
+func testIllegalTransferTransitions(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	newPending := func() Transfer {
+		result, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+		require.NoError(t, err)
+		return result.Transfer
+	}
+
+	posted := newPending()
+	_, err := store.PostTransferTx(ctx, posted.ID)
+	require.NoError(t, err)
+
+	failed := newPending()
+	_, err = store.VoidTransferTx(ctx, failed.ID)
+	require.NoError(t, err)
+
+	direct, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+	require.NoError(t, err)
+
+	pending := newPending()
+	requireAvailableBalance(t, store, account1.ID, 80, 70)
+	requireAvailableBalance(t, store, account2.ID, 20, 20)
+
+	testCases := []struct {
+		name     string
+		transfer Transfer
+		run      func(transferID int64) error
+	}{
+		{
+			name:     "PostPosted",
+			transfer: posted,
+			run:      func(id int64) error { _, err := store.PostTransferTx(ctx, id); return err },
+		},
+		{
+			name:     "PostDirect",
+			transfer: direct.Transfer,
+			run:      func(id int64) error { _, err := store.PostTransferTx(ctx, id); return err },
+		},
+		{
+			name:     "PostFailed",
+			transfer: failed,
+			run:      func(id int64) error { _, err := store.PostTransferTx(ctx, id); return err },
+		},
+		{
+			name:     "VoidPosted",
+			transfer: posted,
+			run:      func(id int64) error { _, err := store.VoidTransferTx(ctx, id); return err },
+		},
+		{
+			name:     "VoidFailed",
+			transfer: failed,
+			run:      func(id int64) error { _, err := store.VoidTransferTx(ctx, id); return err },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.run(tc.transfer.ID)
+			require.ErrorIs(t, err, ErrInvalidTransferTransition)
+		})
+	}
+
+	// only posted transfers can be reversed
+	for _, transfer := range []Transfer{pending, failed} {
+		_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: transfer.ID})
+		require.ErrorIs(t, err, ErrTransferNotPosted)
+	}
+
+	// none of the refused transitions moved money or released a hold
+	requireAvailableBalance(t, store, account1.ID, 80, 70)
+	requireAvailableBalance(t, store, account2.ID, 20, 20)
+
+	got, err := store.GetTransfer(ctx, pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, got.Status)
+}
+
+func TestPostTransferTxConcurrent(t *testing.T) {
+	forEachStore(t, testPostTransferTxConcurrent)
+}
+
+func testPostTransferTxConcurrent(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	pending, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 40})
+	require.NoError(t, err)
+
+	n := 6
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			var err error
+			if i%2 == 0 {
+				_, err = store.PostTransferTx(ctx, pending.Transfer.ID)
+			} else {
+				_, err = store.VoidTransferTx(ctx, pending.Transfer.ID)
+			}
+			errs <- err
+		}(i)
+	}
+
+	settled := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			settled++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInvalidTransferTransition)
+	}
+	require.Equal(t, 1, settled)
+
+	transfer, err := store.GetTransfer(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+	if transfer.Status == TransferStatusPosted {
+		requireAvailableBalance(t, store, account1.ID, 60, 60)
+		requireAvailableBalance(t, store, account2.ID, 40, 40)
+	} else {
+		require.Equal(t, TransferStatusFailed, transfer.Status)
+		requireAvailableBalance(t, store, account1.ID, 100, 100)
+		requireAvailableBalance(t, store, account2.ID, 0, 0)
+	}
+}
@@ -10,20 +10,65 @@ import (
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldBalance(ctx context.Context, arg AddAccountHeldBalanceParams) (Account, error)
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	CountPendingTransfersByAccount(ctx context.Context, accountID int64) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (OutboxEvent, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteAccount(ctx context.Context, id int64) (Account, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetEntriesTotalSince(ctx context.Context, arg GetEntriesTotalSinceParams) (int64, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetOutboxEvent(ctx context.Context, id int64) (OutboxEvent, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetUser(ctx context.Context, username string) (User, error)
+	// Returns a batch of accounts with the sum of their entries, which should equal the balance.
+	ListAccountEntryTotals(ctx context.Context, arg ListAccountEntryTotalsParams) ([]ListAccountEntryTotalsRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error)
+	ListAccountsByOwnerPage(ctx context.Context, arg ListAccountsByOwnerPageParams) ([]Account, error)
+	ListAccountsPage(ctx context.Context, arg ListAccountsPageParams) ([]Account, error)
+	ListActiveHoldsByAccount(ctx context.Context, accountID int64) ([]Hold, error)
+	ListAuditLogByEntity(ctx context.Context, arg ListAuditLogByEntityParams) ([]AuditLog, error)
 	ListEntriesByAccount(ctx context.Context, arg ListEntriesByAccountParams) ([]Entry, error)
+	ListEntriesByAccountPage(ctx context.Context, arg ListEntriesByAccountPageParams) ([]Entry, error)
+	// Returns the legs of a transfer: the debit of the source account, then the credit of the destination account.
+	ListEntriesByTransfer(ctx context.Context, transferID int64) ([]Entry, error)
+	// Returns a batch of entries with the number of transfers each of them is a leg of, which is 0 or 1.
+	ListEntryTransferMatches(ctx context.Context, arg ListEntryTransferMatchesParams) ([]ListEntryTransferMatchesRow, error)
+	// Locks a batch of active holds that expired at or before expires_before,
+	// skipping the ones another transaction is settling.
+	ListExpiredHoldsForUpdate(ctx context.Context, arg ListExpiredHoldsForUpdateParams) ([]Hold, error)
+	ListOutboxEventsByAggregate(ctx context.Context, arg ListOutboxEventsByAggregateParams) ([]OutboxEvent, error)
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	// Returns a batch of transfers with the number of debit and credit entries linked to each of them.
+	ListTransferEntryMatches(ctx context.Context, arg ListTransferEntryMatchesParams) ([]ListTransferEntryMatchesRow, error)
+	ListTransferReversals(ctx context.Context, transferID int64) ([]Transfer, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
 	ListTransfersFromAccount(ctx context.Context, arg ListTransfersFromAccountParams) ([]Transfer, error)
+	ListTransfersFromAccountPage(ctx context.Context, arg ListTransfersFromAccountPageParams) ([]Transfer, error)
+	ListTransfersPage(ctx context.Context, arg ListTransfersPageParams) ([]Transfer, error)
 	ListTransfersToAccount(ctx context.Context, arg ListTransfersToAccountParams) ([]Transfer, error)
+	ListTransfersToAccountPage(ctx context.Context, arg ListTransfersToAccountPageParams) ([]Transfer, error)
+	// Locks the oldest undelivered events, skipping the ones another relay is delivering.
+	ListUndeliveredOutboxEventsForUpdate(ctx context.Context, limit int32) ([]OutboxEvent, error)
+	MarkOutboxEventDelivered(ctx context.Context, id int64) (OutboxEvent, error)
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) (OutboxEvent, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
 }
 
 var _ Querier = (*Queries)(nil)
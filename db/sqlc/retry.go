@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"expvar"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy controls how often a transaction is re-run after Postgres aborted it
+// with a serialization failure or a deadlock. The wait before each retry is picked at random
+// between zero and BaseDelay doubled per attempt, capped at MaxDelay.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of runs, including the first one. Values below 1 mean a single run.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy is used by NewStore.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   10 * time.Millisecond,
+	MaxDelay:    500 * time.Millisecond,
+}
+
+// TxRetries counts retried transactions by Postgres error code,
+// and transactions that still failed after the last attempt under "exhausted".
+// It is published with expvar, so it shows up under /debug/vars wherever expvar is served.
+var TxRetries = expvar.NewMap("simplebank_tx_retries")
+
+// retryableError returns the Postgres error code of err if re-running the transaction may succeed.
+func retryableError(err error) (string, bool) {
+	switch code := ErrorCode(err); code {
+	case SerializationFailure, DeadlockDetected:
+		return code, true
+	default:
+		return "", false
+	}
+}
+
+// retryTx calls run until it succeeds, fails with an error that is not retryable,
+// policy.MaxAttempts is reached or ctx is done.
+func retryTx(ctx context.Context, policy RetryPolicy, run func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := run()
+		code, retryable := retryableError(err)
+		if !retryable {
+			return err
+		}
+		if attempt >= policy.MaxAttempts {
+			TxRetries.Add("exhausted", 1)
+			return err
+		}
+
+		TxRetries.Add(code, 1)
+		timer := time.NewTimer(policy.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns a random delay before the retry that follows the given attempt.
+func (policy RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.MaxDelay
+	if attempt < 32 {
+		// a shift that overflows turns negative and keeps MaxDelay
+		if d := policy.BaseDelay << (attempt - 1); d >= 0 && d < delay {
+			delay = d
+		}
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
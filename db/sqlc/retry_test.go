@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    5 * time.Millisecond,
+}
+
+func retryCount(key string) int64 {
+	if v, ok := TxRetries.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestRetryTx(t *testing.T) {
+	for _, code := range []string{SerializationFailure, DeadlockDetected} {
+		code := code
+		t.Run(code, func(t *testing.T) {
+			retries := retryCount(code)
+
+			calls := 0
+			err := retryTx(context.Background(), testRetryPolicy, func() error {
+				calls++
+				if calls < 3 {
+					return &pq.Error{Code: pq.ErrorCode(code)}
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			require.Equal(t, 3, calls)
+			require.Equal(t, retries+2, retryCount(code))
+		})
+	}
+}
+
+func TestRetryTxNotRetryable(t *testing.T) {
+	calls := 0
+	err := retryTx(context.Background(), testRetryPolicy, func() error {
+		calls++
+		return ErrInsufficientFunds
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Equal(t, 1, calls)
+}
+
+func TestRetryTxExhausted(t *testing.T) {
+	exhausted := retryCount("exhausted")
+
+	calls := 0
+	err := retryTx(context.Background(), testRetryPolicy, func() error {
+		calls++
+		return &pq.Error{Code: SerializationFailure}
+	})
+	require.Equal(t, SerializationFailure, ErrorCode(err))
+	require.Equal(t, testRetryPolicy.MaxAttempts, calls)
+	require.Equal(t, exhausted+1, retryCount("exhausted"))
+}
+
+func TestRetryTxContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	calls := 0
+	err := retryTx(ctx, policy, func() error {
+		calls++
+		cancel()
+		return &pq.Error{Code: DeadlockDetected}
+	})
+	require.Equal(t, DeadlockDetected, ErrorCode(err))
+	require.Equal(t, 1, calls)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for attempt := 1; attempt < 100; attempt++ {
+		delay := policy.backoff(attempt)
+		require.GreaterOrEqual(t, delay, time.Duration(0))
+		require.LessOrEqual(t, delay, policy.MaxDelay)
+		if attempt == 1 {
+			require.LessOrEqual(t, delay, policy.BaseDelay)
+		}
+	}
+
+	require.Zero(t, RetryPolicy{}.backoff(1))
+}
+
+// TestExecTxSerializable runs conflicting serializable transactions against Postgres,
+// which aborts all but one of them with 40001 on every round; the retries must get all of them through.
+func TestExecTxSerializable(t *testing.T) {
+	store := NewStoreWithRetryPolicy(testDB, RetryPolicy{
+		MaxAttempts: 50,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    20 * time.Millisecond,
+	}).(*SQLStore)
+	account := createRandomAccount(t)
+
+	n := 5
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- store.execTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelSerializable}, func(q Querier) error {
+				got, err := q.GetAccount(context.Background(), account.ID)
+				if err != nil {
+					return err
+				}
+				_, err = q.UpdateAccount(context.Background(), UpdateAccountParams{
+					ID:      account.ID,
+					Balance: got.Balance + 1,
+				})
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	requireBalance(t, store, account.ID, account.Balance+int64(n))
+}
+
+func TestExecTxReadOnly(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account := createRandomAccount(t)
+
+	err := store.execTx(context.Background(), &sql.TxOptions{ReadOnly: true}, func(q Querier) error {
+		_, err := q.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 0})
+		return err
+	})
+	var pqErr *pq.Error
+	require.ErrorAs(t, err, &pqErr)
+	requireBalance(t, store, account.ID, account.Balance)
+}
@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTransferReversed is returned when a transfer has already been reversed in full.
+	ErrTransferReversed = errors.New("transfer already reversed")
+	// ErrReversalExceedsTransfer is returned when a reversal would send back more than what is left of the transfer.
+	ErrReversalExceedsTransfer = errors.New("reversal exceeds transfer amount")
+	// ErrReversalNotReversible is returned when asked to reverse a reversal.
+	ErrReversalNotReversible = errors.New("a reversal cannot be reversed")
+	// ErrTransferNotPosted is returned when asked to reverse a transfer that never moved money.
+	ErrTransferNotPosted = errors.New("transfer is not posted")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is the part of the transfer to send back. Zero reverses whatever has not been reversed yet.
+	Amount Amount `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+type ReverseTransferTxResult struct {
+	// OriginalTransfer is the reversed transfer, with its updated ReversedAmount.
+	OriginalTransfer Transfer `json:"original_transfer"`
+	// Reversal is the transfer sending the money back, from the original destination to the original source.
+	Reversal TransferTxResult `json:"reversal"`
+}
+
+// ReverseTransferTx sends the money of a transfer back, in full or in part.
+// It locks the original transfer, checks that the reversal fits in what has not been reversed yet,
+// then creates an opposite transfer linked to the original and adds its amount to the original's ReversedAmount,
+// all within a single database transaction. The reversal moves money like TransferTx does,
+// so it fails with ErrInsufficientFunds when the original destination cannot cover it.
+// A cross-currency transfer is reversed in full, at its original amounts.
+func (store txStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+	if err := arg.Amount.validateOptional(); err != nil {
+		return result, fmt.Errorf("reversal: %w", err)
+	}
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		// Lock the original first, so that concurrent reversals of the same transfer queue up
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if original.Status != TransferStatusPosted {
+			return fmt.Errorf("%w: transfer %d is %s", ErrTransferNotPosted, original.ID, original.Status)
+		}
+		if original.ReversalOf.Valid {
+			return fmt.Errorf("%w: transfer %d reverses transfer %d",
+				ErrReversalNotReversible, original.ID, original.ReversalOf.Int64)
+		}
+
+		left := original.Amount - original.ReversedAmount
+		if left == 0 {
+			return fmt.Errorf("%w: transfer %d", ErrTransferReversed, original.ID)
+		}
+		amount := int64(arg.Amount)
+		if amount == 0 {
+			amount = left
+		}
+		if amount > left {
+			return fmt.Errorf("%w: transfer %d has %d left to reverse, asked for %d",
+				ErrReversalExceedsTransfer, original.ID, left, amount)
+		}
+		if original.IsExchange() && amount != original.Amount {
+			return fmt.Errorf("%w: transfer %d", ErrPartialExchangeReversal, original.ID)
+		}
+
+		result.Reversal, err = transfer(ctx, q, reversalParams(original, amount, arg.Reason))
+		if err != nil {
+			return err
+		}
+
+		result.OriginalTransfer, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			ID:     original.ID,
+			Amount: amount,
+		})
+		return err
+	})
+
+	return result, err
+}
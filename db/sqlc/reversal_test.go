@@ -0,0 +1,146 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simplebank/db/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTx(t *testing.T) {
+	forEachStore(t, testReverseTransferTx)
+}
+
+func testReverseTransferTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 100)
+
+	original, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID, Reason: "duplicate payment"})
+	require.NoError(t, err)
+
+	require.Equal(t, original.Transfer.ID, result.OriginalTransfer.ID)
+	require.Equal(t, int64(30), result.OriginalTransfer.ReversedAmount)
+
+	reversal := result.Reversal.Transfer
+	require.Equal(t, account2.ID, reversal.FromAccountID)
+	require.Equal(t, account1.ID, reversal.ToAccountID)
+	require.Equal(t, int64(30), reversal.Amount)
+	require.Equal(t, sql.NullInt64{Int64: original.Transfer.ID, Valid: true}, reversal.ReversalOf)
+	require.Equal(t, sql.NullString{String: "duplicate payment", Valid: true}, reversal.Reason)
+
+	require.Equal(t, int64(-30), result.Reversal.FromEntry.Amount)
+	require.Equal(t, int64(30), result.Reversal.ToEntry.Amount)
+	require.Equal(t, sql.NullInt64{Int64: reversal.ID, Valid: true}, result.Reversal.ToEntry.TransferID)
+	requireBalance(t, store, account1.ID, 100)
+	requireBalance(t, store, account2.ID, 100)
+
+	reversals, err := store.ListTransferReversals(ctx, original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{reversal}, reversals)
+
+	// a transfer is reversed once
+	_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.ErrorIs(t, err, ErrTransferReversed)
+
+	// and a reversal is not reversed at all
+	_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: reversal.ID})
+	require.ErrorIs(t, err, ErrReversalNotReversible)
+	requireBalance(t, store, account1.ID, 100)
+	requireBalance(t, store, account2.ID, 100)
+
+	_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: -1})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestReverseTransferTxPartial(t *testing.T) {
+	forEachStore(t, testReverseTransferTxPartial)
+}
+
+func testReverseTransferTxPartial(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	original, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 50})
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID, Amount: 20})
+	require.NoError(t, err)
+	require.Equal(t, int64(20), result.OriginalTransfer.ReversedAmount)
+
+	_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID, Amount: 31})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID, Amount: -1})
+	require.ErrorIs(t, err, ErrInvalidAmount)
+
+	// the destination spent part of the money, so the rest cannot be sent back
+	_, err = store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account2.ID, Amount: 25})
+	require.NoError(t, err)
+	_, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	transfer, err := store.GetTransfer(ctx, original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(20), transfer.ReversedAmount)
+
+	// zero reverses what is left
+	_, err = store.DepositTx(ctx, DepositTxParams{AccountID: account2.ID, Amount: 25})
+	require.NoError(t, err)
+	result, err = store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), result.Reversal.Transfer.Amount)
+	require.Equal(t, int64(50), result.OriginalTransfer.ReversedAmount)
+	requireBalance(t, store, account1.ID, 100)
+	requireBalance(t, store, account2.ID, 0)
+
+	reversals, err := store.ListTransferReversals(ctx, original.Transfer.ID)
+	require.NoError(t, err)
+	require.Len(t, reversals, 2)
+}
+
+func TestReverseTransferTxConcurrent(t *testing.T) {
+	forEachStore(t, testReverseTransferTxConcurrent)
+}
+
+func testReverseTransferTxConcurrent(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 100)
+
+	original, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 40})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(ctx, ReverseTransferTxParams{TransferID: original.Transfer.ID})
+			errs <- err
+		}()
+	}
+
+	reversed := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			reversed++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferReversed)
+	}
+
+	// the money went back exactly once
+	require.Equal(t, 1, reversed)
+	requireBalance(t, store, account1.ID, 100)
+	requireBalance(t, store, account2.ID, 100)
+}
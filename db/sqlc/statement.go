@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidPeriod is returned when a statement period does not end after it starts.
+var ErrInvalidPeriod = errors.New("invalid statement period")
+
+type GetAccountStatementParams struct {
+	AccountID int64     `json:"account_id"`
+	From      time.Time `json:"from"`
+	To        time.Time `json:"to"`
+}
+
+// StatementLine is an entry of a statement, with the account balance right after it.
+// TransferID and CounterpartyAccountID are zero for entries that do not belong to a transfer.
+type StatementLine struct {
+	Entry                 Entry `json:"entry"`
+	Balance               int64 `json:"balance"`
+	TransferID            int64 `json:"transfer_id,omitempty"`
+	CounterpartyAccountID int64 `json:"counterparty_account_id,omitempty"`
+}
+
+type AccountStatement struct {
+	Account        Account         `json:"account"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance int64           `json:"opening_balance"`
+	Lines          []StatementLine `json:"lines"`
+	ClosingBalance int64           `json:"closing_balance"`
+}
+
+// GetAccountStatement returns the entries of an account created in [From, To), in order,
+// with the balance before the period, after every entry and at the end of the period.
+// Balances are derived from the current balance and the entries written since From,
+// all read from the same snapshot.
+func (store txStore) GetAccountStatement(ctx context.Context, arg GetAccountStatementParams) (AccountStatement, error) {
+	statement := AccountStatement{
+		From: arg.From,
+		To:   arg.To,
+	}
+	if !arg.To.After(arg.From) {
+		return statement, fmt.Errorf("%w: %s is not after %s",
+			ErrInvalidPeriod, arg.To.Format(time.RFC3339), arg.From.Format(time.RFC3339))
+	}
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTx(ctx, opts, func(q Querier) error {
+		var err error
+		statement.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		since, err := q.GetEntriesTotalSince(ctx, GetEntriesTotalSinceParams{
+			AccountID: arg.AccountID,
+			Since:     arg.From,
+		})
+		if err != nil {
+			return err
+		}
+
+		rows, err := q.ListStatementEntries(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			FromTime:  arg.From,
+			ToTime:    arg.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		statement.OpeningBalance = statement.Account.Balance - since
+		statement.Lines = make([]StatementLine, len(rows))
+		balance := statement.OpeningBalance
+		for i, row := range rows {
+			balance += row.Amount
+			line := StatementLine{
+				Entry: Entry{
+					ID:          row.ID,
+					AccountID:   row.AccountID,
+					Amount:      row.Amount,
+					CreatedAt:   row.CreatedAt,
+					TransferID:  row.TransferID,
+					Type:        row.Type,
+					ExternalRef: row.ExternalRef,
+				},
+				Balance:    balance,
+				TransferID: row.TransferID.Int64,
+			}
+			if row.FromAccountID.Int64 == row.AccountID {
+				line.CounterpartyAccountID = row.ToAccountID.Int64
+			} else {
+				line.CounterpartyAccountID = row.FromAccountID.Int64
+			}
+			statement.Lines[i] = line
+		}
+		statement.ClosingBalance = balance
+		return nil
+	})
+
+	return statement, err
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"simplebank/db/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountStatement(t *testing.T) {
+	forEachStore(t, testGetAccountStatement)
+}
+
+func testGetAccountStatement(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account := createFundedAccount(t, store, currency, 1000)
+	payee := createFundedAccount(t, store, currency, 1000)
+	payer := createFundedAccount(t, store, currency, 1000)
+
+	out, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account.ID, ToAccountID: payee.ID, Amount: 100})
+	require.NoError(t, err)
+	in, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: payer.ID, ToAccountID: account.ID, Amount: 30})
+	require.NoError(t, err)
+
+	// the window is wide on purpose: the SQL store stamps rows with the database clock
+	statement, err := store.GetAccountStatement(ctx, GetAccountStatementParams{
+		AccountID: account.ID,
+		From:      time.Now().Add(-time.Hour),
+		To:        time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, statement.Account.ID)
+	require.Equal(t, int64(1000), statement.OpeningBalance)
+	require.Equal(t, int64(930), statement.ClosingBalance)
+
+	require.Len(t, statement.Lines, 2)
+	require.Equal(t, out.FromEntry.ID, statement.Lines[0].Entry.ID)
+	require.Equal(t, int64(-100), statement.Lines[0].Entry.Amount)
+	require.Equal(t, int64(900), statement.Lines[0].Balance)
+	require.Equal(t, out.Transfer.ID, statement.Lines[0].TransferID)
+	require.Equal(t, payee.ID, statement.Lines[0].CounterpartyAccountID)
+
+	require.Equal(t, in.ToEntry.ID, statement.Lines[1].Entry.ID)
+	require.Equal(t, int64(30), statement.Lines[1].Entry.Amount)
+	require.Equal(t, int64(930), statement.Lines[1].Balance)
+	require.Equal(t, in.Transfer.ID, statement.Lines[1].TransferID)
+	require.Equal(t, payer.ID, statement.Lines[1].CounterpartyAccountID)
+
+	// a period after the last entry starts and ends at the current balance
+	statement, err = store.GetAccountStatement(ctx, GetAccountStatementParams{
+		AccountID: account.ID,
+		From:      time.Now().Add(time.Hour),
+		To:        time.Now().Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(930), statement.OpeningBalance)
+	require.Equal(t, int64(930), statement.ClosingBalance)
+	require.NotNil(t, statement.Lines)
+	require.Empty(t, statement.Lines)
+
+	// an entry outside of a transfer has no counterparty
+	deposit, err := store.DepositTx(ctx, DepositTxParams{AccountID: account.ID, Amount: 5})
+	require.NoError(t, err)
+	entry := deposit.Entry
+
+	statement, err = store.GetAccountStatement(ctx, GetAccountStatementParams{
+		AccountID: account.ID,
+		From:      time.Now().Add(-time.Hour),
+		To:        time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, statement.Lines, 3)
+	require.Equal(t, entry.ID, statement.Lines[2].Entry.ID)
+	require.Zero(t, statement.Lines[2].TransferID)
+	require.Zero(t, statement.Lines[2].CounterpartyAccountID)
+	require.Equal(t, int64(935), statement.ClosingBalance)
+}
+
+func TestGetAccountStatementErrors(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		account := createFundedAccount(t, store, utils.RandomCurrency(), utils.RandomMoney())
+		now := time.Now()
+
+		_, err := store.GetAccountStatement(ctx, GetAccountStatementParams{AccountID: account.ID, From: now, To: now})
+		require.ErrorIs(t, err, ErrInvalidPeriod)
+
+		_, err = store.GetAccountStatement(ctx, GetAccountStatementParams{
+			AccountID: -1,
+			From:      now.Add(-time.Hour),
+			To:        now,
+		})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
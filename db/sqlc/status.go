@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrAccountFrozen is returned when money would move into or out of a frozen account.
+	ErrAccountFrozen = errors.New("account is frozen")
+	// ErrAccountClosed is returned when money would move into or out of a closed account, or when it would change status.
+	ErrAccountClosed = errors.New("account is closed")
+	// ErrAccountNotEmpty is returned when closing an account whose balance is not zero.
+	ErrAccountNotEmpty = errors.New("account balance is not zero")
+	// ErrAccountPendingActivity is returned when closing an account with active holds or pending transfers.
+	ErrAccountPendingActivity = errors.New("account has pending activity")
+	// ErrAccountHasHistory is returned when deleting an account with entries or transfers, which must be closed instead.
+	ErrAccountHasHistory = errors.New("account has history")
+)
+
+// checkAccountsActive makes sure that money can move into and out of every account.
+func checkAccountsActive(accounts ...Account) error {
+	for _, account := range accounts {
+		switch account.Status {
+		case AccountStatusFrozen:
+			return fmt.Errorf("%w: account %d", ErrAccountFrozen, account.ID)
+		case AccountStatusClosed:
+			return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+		}
+	}
+	return nil
+}
+
+// FreezeAccount stops all money movements into and out of an account until UnfreezeAccount.
+// Freezing a frozen account changes nothing. It fails with ErrAccountClosed when the account is closed.
+func (store txStore) FreezeAccount(ctx context.Context, accountID int64) (Account, error) {
+	return store.setAccountStatus(ctx, accountID, AccountStatusFrozen)
+}
+
+// UnfreezeAccount lets money move into and out of a frozen account again.
+// Unfreezing an active account changes nothing. It fails with ErrAccountClosed when the account is closed.
+func (store txStore) UnfreezeAccount(ctx context.Context, accountID int64) (Account, error) {
+	return store.setAccountStatus(ctx, accountID, AccountStatusActive)
+}
+
+func (store txStore) setAccountStatus(ctx context.Context, accountID int64, status AccountStatus) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		var err error
+		account, err = q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if account.Status == AccountStatusClosed {
+			return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+		}
+		if account.Status == status {
+			return nil
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			Status: status,
+			ID:     accountID,
+		})
+		return err
+	})
+
+	return account, err
+}
+
+// CloseAccountTx closes an account for good, keeping its entries and transfers.
+// It locks the account and requires a zero balance, no active hold and no pending transfer from or to it,
+// so that closing never strands money. A frozen account can be closed.
+// It fails with ErrAccountClosed when the account is already closed.
+func (store txStore) CloseAccountTx(ctx context.Context, accountID int64) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		var err error
+		account, err = q.GetAccountForUpdate(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if account.Status == AccountStatusClosed {
+			return fmt.Errorf("%w: account %d", ErrAccountClosed, account.ID)
+		}
+		if account.Balance != 0 {
+			return fmt.Errorf("%w: account %d has %d", ErrAccountNotEmpty, account.ID, account.Balance)
+		}
+		if account.HeldBalance != 0 {
+			return fmt.Errorf("%w: account %d has %d held", ErrAccountPendingActivity, account.ID, account.HeldBalance)
+		}
+
+		// A pending transfer to the account holds nothing on it, but would credit it once posted
+		pending, err := q.CountPendingTransfersByAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if pending > 0 {
+			return fmt.Errorf("%w: account %d has %d pending transfers", ErrAccountPendingActivity, account.ID, pending)
+		}
+
+		account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			Status: AccountStatusClosed,
+			ID:     accountID,
+		})
+		return err
+	})
+
+	return account, err
+}
+
+// deleteUnusedAccount deletes an account that was never used. An account with entries or transfers keeps its history
+// and can only be closed with CloseAccountTx, so deleting it fails with ErrAccountHasHistory.
+func deleteUnusedAccount(ctx context.Context, q Querier, accountID int64) (Account, error) {
+	account, err := q.GetAccountForUpdate(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	entries, err := q.ListEntriesByAccount(ctx, ListEntriesByAccountParams{AccountID: accountID, Limit: 1})
+	if err != nil {
+		return account, err
+	}
+	transfers, err := q.ListTransfers(ctx, ListTransfersParams{FromAccountID: accountID, ToAccountID: accountID, Limit: 1})
+	if err != nil {
+		return account, err
+	}
+	if len(entries) > 0 || len(transfers) > 0 {
+		return account, fmt.Errorf("%w: account %d cannot be deleted, close it instead", ErrAccountHasHistory, accountID)
+	}
+
+	return q.DeleteAccount(ctx, accountID)
+}
@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"simplebank/db/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeAccount(t *testing.T) {
+	forEachStore(t, testFreezeAccount)
+}
+
+func testFreezeAccount(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 100)
+	require.Equal(t, AccountStatusActive, account1.Status)
+
+	frozen, err := store.FreezeAccount(ctx, account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+
+	// freezing twice changes nothing
+	frozen, err = store.FreezeAccount(ctx, account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+
+	// money can move neither in nor out
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	_, err = store.DepositTx(ctx, DepositTxParams{AccountID: account2.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	_, err = store.PlaceHold(ctx, PlaceHoldParams{AccountID: account2.ID, Amount: 10, ExpiresAt: time.Now().Add(time.Hour)})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	_, err = store.BatchTransferTx(ctx, BatchTransferTxParams{Legs: []BatchTransferLeg{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+	}})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+	requireBalance(t, store, account1.ID, 100)
+	requireBalance(t, store, account2.ID, 100)
+
+	unfrozen, err := store.UnfreezeAccount(ctx, account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, unfrozen.Status)
+
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+	require.NoError(t, err)
+	requireBalance(t, store, account2.ID, 110)
+}
+
+func TestPostTransferToFrozenAccount(t *testing.T) {
+	forEachStore(t, testPostTransferToFrozenAccount)
+}
+
+func testPostTransferToFrozenAccount(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	pending, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+
+	_, err = store.FreezeAccount(ctx, account2.ID)
+	require.NoError(t, err)
+
+	_, err = store.PostTransferTx(ctx, pending.Transfer.ID)
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	// voiding gives the money back, which a freeze does not prevent
+	_, err = store.VoidTransferTx(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+	requireAvailableBalance(t, store, account1.ID, 100, 100)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	forEachStore(t, testCloseAccountTx)
+}
+
+func testCloseAccountTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+
+	_, err := store.CloseAccountTx(ctx, account1.ID)
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	// a pending transfer to the account would credit it once posted
+	pending, err := store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+	_, err = store.CloseAccountTx(ctx, account2.ID)
+	require.ErrorIs(t, err, ErrAccountPendingActivity)
+	_, err = store.VoidTransferTx(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+
+	transfer, err := store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+	require.NoError(t, err)
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10})
+	require.NoError(t, err)
+
+	// a frozen account can be closed
+	_, err = store.FreezeAccount(ctx, account2.ID)
+	require.NoError(t, err)
+
+	closed, err := store.CloseAccountTx(ctx, account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, closed.Status)
+
+	// the history stays
+	_, err = store.GetEntry(ctx, transfer.ToEntry.ID)
+	require.NoError(t, err)
+	_, err = store.DeleteAccount(ctx, account2.ID)
+	require.ErrorIs(t, err, ErrAccountHasHistory)
+
+	// closed is final
+	_, err = store.CloseAccountTx(ctx, account2.ID)
+	require.ErrorIs(t, err, ErrAccountClosed)
+	_, err = store.UnfreezeAccount(ctx, account2.ID)
+	require.ErrorIs(t, err, ErrAccountClosed)
+	_, err = store.TransferTx(ctx, TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	// the owner may open another account in the same currency
+	reopened, err := store.CreateAccount(ctx, CreateAccountParams{Owner: account2.Owner, Currency: currency})
+	require.NoError(t, err)
+
+	accounts, err := store.ListAccountsByOwner(ctx, ListAccountsByOwnerParams{
+		Owner:  account2.Owner,
+		Status: NullAccountStatus{AccountStatus: AccountStatusActive, Valid: true},
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, reopened.ID, accounts[0].ID)
+
+	accounts, err = store.ListAccountsByOwner(ctx, ListAccountsByOwnerParams{Owner: account2.Owner, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+}
+
+func TestDeleteAccountWithTransfers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+		currency := utils.RandomCurrency()
+		account1 := createFundedAccount(t, store, currency, 0)
+		account2 := createFundedAccount(t, store, currency, 0)
+
+		// a transfer without entries still belongs to the history of both accounts
+		_, err := store.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+			Status:        TransferStatusFailed,
+		})
+		require.NoError(t, err)
+
+		_, err = store.DeleteAccount(ctx, account1.ID)
+		require.ErrorIs(t, err, ErrAccountHasHistory)
+		_, err = store.DeleteAccount(ctx, account2.ID)
+		require.ErrorIs(t, err, ErrAccountHasHistory)
+
+		requireBalance(t, store, account1.ID, 0)
+
+		// an account that was never used can still be deleted
+		account3 := createFundedAccount(t, store, currency, 0)
+		deleted, err := store.DeleteAccount(ctx, account3.ID)
+		require.NoError(t, err)
+		require.Equal(t, account3.ID, deleted.ID)
+	})
+}
@@ -3,24 +3,105 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
+
+	"simplebank/currency"
+	"simplebank/fx"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when the accounts of a transfer hold different currencies.
+	ErrCurrencyMismatch = errors.New("account currency mismatch")
+	// ErrInsufficientFunds is returned when the source account cannot cover the transfer amount.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrInvalidAmount is returned when the amount of a transfer, deposit, withdrawal or hold is not positive.
+	// It is currency.ErrInvalidAmount, so that both match the same errors.
+	ErrInvalidAmount = currency.ErrInvalidAmount
+	// ErrAmountOverflow is returned when a balance would no longer fit in a bigint.
+	// It is currency.ErrOverflow, so that both match the same errors.
+	ErrAmountOverflow = currency.ErrOverflow
 )
 
 // Store provides all functions to execute db queries and transactions
-type Store struct {
+type Store interface {
+	Querier
+	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (CashTxResult, error)
+	CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParams) (PendingTransferTxResult, error)
+	PostTransferTx(ctx context.Context, transferID int64) (TransferTxResult, error)
+	VoidTransferTx(ctx context.Context, transferID int64) (PendingTransferTxResult, error)
+	ExchangeTransferTx(ctx context.Context, arg ExchangeTransferTxParams) (TransferTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	DeliverOutboxEvents(ctx context.Context, limit int32, deliver DeliverFunc) (int, error)
+	PlaceHold(ctx context.Context, arg PlaceHoldParams) (HoldTxResult, error)
+	ReleaseHold(ctx context.Context, holdID int64) (HoldTxResult, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error)
+	ExpireHolds(ctx context.Context, asOf time.Time) (int, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	GetAccountStatement(ctx context.Context, arg GetAccountStatementParams) (AccountStatement, error)
+	CloseAccountTx(ctx context.Context, accountID int64) (Account, error)
+	FreezeAccount(ctx context.Context, accountID int64) (Account, error)
+	UnfreezeAccount(ctx context.Context, accountID int64) (Account, error)
+}
+
+// txStore implements the transactional methods of Store on top of a transaction runner,
+// so that every Store implementation enforces the same business rules.
+type txStore struct {
+	execTx func(ctx context.Context, opts *sql.TxOptions, fn func(Querier) error) error
+	// rates prices ExchangeTransferTx. Without it, cross-currency transfers fail with ErrNoRateProvider.
+	rates fx.RateProvider
+}
+
+// SQLStore provides all functions to execute SQL queries and transactions
+type SQLStore struct {
 	*Queries
-	db *sql.DB
+	txStore
+	db    *sql.DB
+	retry RetryPolicy
+}
+
+// NewStore creates a Store backed by a SQL database, retrying transactions with DefaultRetryPolicy
+func NewStore(db *sql.DB) Store {
+	return NewStoreWithRetryPolicy(db, DefaultRetryPolicy)
+}
+
+// NewStoreWithRetryPolicy creates a Store backed by a SQL database, retrying transactions with the given policy
+func NewStoreWithRetryPolicy(db *sql.DB, policy RetryPolicy) Store {
+	return newSQLStore(db, policy, nil)
 }
 
-func NewStore(db *sql.DB) *Store {
-	return &Store{
+// NewStoreWithRateProvider creates a Store backed by a SQL database that prices cross-currency transfers with rates,
+// retrying transactions with DefaultRetryPolicy
+func NewStoreWithRateProvider(db *sql.DB, rates fx.RateProvider) Store {
+	return newSQLStore(db, DefaultRetryPolicy, rates)
+}
+
+func newSQLStore(db *sql.DB, policy RetryPolicy, rates fx.RateProvider) *SQLStore {
+	store := &SQLStore{
 		db:      db,
 		Queries: New(db),
+		retry:   policy,
 	}
+	store.txStore = txStore{execTx: auditTx(store.execTx), rates: rates}
+	return store
 }
 
-func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// execTx runs fn inside a transaction started with opts, which may be nil for the defaults.
+// If Postgres aborts the transaction with a serialization failure or a deadlock, the whole transaction
+// is rolled back and fn runs again according to the store's retry policy, so fn must not have side effects
+// outside of q.
+func (store *SQLStore) execTx(ctx context.Context, opts *sql.TxOptions, fn func(Querier) error) error {
+	return retryTx(ctx, store.retry, func() error {
+		return store.runTx(ctx, opts, fn)
+	})
+}
+
+func (store *SQLStore) runTx(ctx context.Context, opts *sql.TxOptions, fn func(Querier) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -30,16 +111,17 @@ func (store *Store) execTx(ctx context.Context, fn func(*Queries) error) error {
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
 		}
-		return err
+		return constraintError(err)
 	}
-	return tx.Commit()
+	return constraintError(tx.Commit())
 }
 
-
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        Amount `json:"amount"`
+	// IdempotencyKey is optional. A retry with the same key gets the original result instead of a second transfer.
+	IdempotencyKey string `json:"idempotency_key"`
 }
 
 type TransferTxResult struct {
@@ -51,102 +133,220 @@ type TransferTxResult struct {
 }
 
 type AddMoneyParams struct {
-    ID int64
-    accountID1 int64
-    amount1 int64
-    accountID2 int64
-    amount2 int64
+	ID         int64
+	accountID1 int64
+	amount1    int64
+	accountID2 int64
+	amount2    int64
 }
 
-
 // TransferTx performs a money transfer from one account to the other.
-// It creates a transfer record, add accoubnt entry, and update accounts' balance with new values within a single database transaction.
-// If any of the operations fail, it returns an error.
-func (store *Store) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
-    var result TransferTxResult
-
-    err := store.execTx(ctx, func(q *Queries) error {
-        var err error
-
-
-        // Step 1: Create a new entry in the transfers table
-        result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-            FromAccountID: arg.FromAccountID,
-            ToAccountID:   arg.ToAccountID,
-            Amount:        arg.Amount,
-        })
-        if err != nil {
-            return err
-        }
-
-        // Step 2: Create entries in the account_entries table
-        result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-            AccountID: arg.FromAccountID,
-            Amount:    -arg.Amount,
-        })
-        if err != nil {
-            return err
-        }
-
-        result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-            AccountID: arg.ToAccountID,
-            Amount:    arg.Amount,
-        })
-        if err != nil {
-            return err
-        }
-
-        if arg.FromAccountID < arg.ToAccountID {
-
-            result.FromAccount, result.ToAccount, err = addMoney(ctx, q, AddMoneyParams{
-                accountID1: arg.FromAccountID,
-                amount1: -arg.Amount,
-                accountID2: arg.ToAccountID,
-                amount2: arg.Amount,
-            })
-
-            if err != nil {
-                return err
-            }
-            
-        } else {
-            result.ToAccount, result.FromAccount, err = addMoney(ctx, q, AddMoneyParams{
-                accountID1: arg.ToAccountID,
-                amount1: arg.Amount,
-                accountID2: arg.FromAccountID,
-                amount2: -arg.Amount,
-            })
-
-            if err != nil {
-                return err
-            }
-        }
-
-        return nil
-    })
-
-    return result, err
-}
-
-
-func addMoney(ctx context.Context, q *Queries, arg AddMoneyParams) (account1 Account, account2 Account, err error) {
-    account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-        ID:      arg.accountID1,
-        Amount: arg.amount1,
-    })
-
-    if err != nil {
-        return
-    }
-
-    account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-        ID:      arg.accountID2,
-        Amount: arg.amount2,
-    })
-    if err != nil {
-        return
-    }
-
-    return
-
-}
\ No newline at end of file
+// It locks both accounts, checks that they share a currency and that the available balance of the source account
+// covers the amount,
+// then creates a transfer record, add account entries, and update accounts' balance within a single database transaction.
+// If any of the operations fail, it returns an error and nothing is written.
+//
+// When arg.IdempotencyKey is set, the result is saved under the key in the same transaction.
+// Replaying the key with the same parameters returns the saved result without moving money again,
+// and replaying it with different parameters returns ErrIdempotencyKeyConflict.
+func (store txStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	if err := arg.Amount.Validate(); err != nil {
+		return result, fmt.Errorf("transfer: %w", err)
+	}
+
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		if arg.IdempotencyKey != "" {
+			var found bool
+			var err error
+			result, found, err = replayTransfer(ctx, q, arg)
+			if err != nil || found {
+				return err
+			}
+		}
+
+		// Steps 1 to 5: move the money
+		var err error
+		result, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        int64(arg.Amount),
+			Status:        TransferStatusPosted,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Step 6: Remember the result, so that a retry does not move the money again
+		if arg.IdempotencyKey != "" {
+			return saveIdempotencyKey(ctx, q, arg, result)
+		}
+
+		return nil
+	})
+
+	// A concurrent request with the same key committed first, and this transaction was rolled back.
+	// Answer with what the winner saved.
+	if isIdempotencyKeyViolation(err) {
+		err = store.execTx(ctx, &sql.TxOptions{ReadOnly: true}, func(q Querier) error {
+			var found bool
+			var err error
+			result, found, err = replayTransfer(ctx, q, arg)
+			if err == nil && !found {
+				err = sql.ErrNoRows
+			}
+			return err
+		})
+	}
+
+	return result, err
+}
+
+// transfer runs steps 1 to 5 of TransferTx inside the caller's transaction: it locks and validates both accounts,
+// then writes the transfer described by arg, its two entries and the new balances.
+func transfer(ctx context.Context, q Querier, arg CreateTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	// Step 1: Lock both accounts in a consistent order to avoid deadlocks
+	fromAccount, toAccount, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	// Step 2: Validate the transfer against the locked rows
+	if err := checkTransfer(fromAccount, toAccount, arg); err != nil {
+		return result, err
+	}
+
+	// Step 3: Create a new entry in the transfers table
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+
+	// Steps 4 and 5
+	err = postTransfer(ctx, q, &result)
+	return result, err
+}
+
+// checkTransfer makes sure that arg can move money between the two accounts:
+// the amount is positive, both accounts are active, they share a currency, unless arg converts between currencies,
+// the available balance of the source covers the amount and the destination balance does not overflow.
+func checkTransfer(fromAccount, toAccount Account, arg CreateTransferParams) error {
+	amount, credit := arg.Amount, arg.Amount
+	if arg.ToAmount.Valid {
+		credit = arg.ToAmount.Int64
+	}
+	if err := Amount(amount).Validate(); err != nil {
+		return fmt.Errorf("transfer: %w", err)
+	}
+	if err := Amount(credit).Validate(); err != nil {
+		return fmt.Errorf("transfer credit: %w", err)
+	}
+	if err := checkAccountsActive(fromAccount, toAccount); err != nil {
+		return err
+	}
+	if fromAccount.Currency != toAccount.Currency && !arg.ToAmount.Valid {
+		return fmt.Errorf("%w: account %d is %s, account %d is %s",
+			ErrCurrencyMismatch, fromAccount.ID, fromAccount.Currency, toAccount.ID, toAccount.Currency)
+	}
+	if fromAccount.AvailableBalance < amount {
+		return fmt.Errorf("%w: account %d has %d available, needs %d",
+			ErrInsufficientFunds, fromAccount.ID, fromAccount.AvailableBalance, amount)
+	}
+	return checkBalanceChange(toAccount, credit)
+}
+
+// postTransfer writes the two entries of result.Transfer, moves its amount between the balances
+// and records the outbox events, filling in the rest of result. Both accounts must already be locked.
+func postTransfer(ctx context.Context, q Querier, result *TransferTxResult) error {
+	arg := result.Transfer
+
+	// Step 4: Create entries in the account_entries table, both linked to the transfer
+	transferID := sql.NullInt64{Int64: arg.ID, Valid: true}
+	var err error
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		Type:       EntryTypeTransfer,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.CreditAmount(),
+		Type:       EntryTypeTransfer,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Step 5: Update the balances, again in a consistent order
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, AddMoneyParams{
+			accountID1: arg.FromAccountID,
+			amount1:    -arg.Amount,
+			accountID2: arg.ToAccountID,
+			amount2:    arg.CreditAmount(),
+		})
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, AddMoneyParams{
+			accountID1: arg.ToAccountID,
+			amount1:    arg.CreditAmount(),
+			accountID2: arg.FromAccountID,
+			amount2:    -arg.Amount,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// Record the events of the transfer, published once the transaction commits
+	return writeTransferEvents(ctx, q, *result)
+}
+
+// writeTransferEvents records that the two entries of result were posted and its transfer completed.
+func writeTransferEvents(ctx context.Context, q Querier, result TransferTxResult) error {
+	for _, entry := range []Entry{result.FromEntry, result.ToEntry} {
+		if err := writeEvent(ctx, q, EventTypeEntryPosted, entry.ID, entry); err != nil {
+			return err
+		}
+	}
+	return writeEvent(ctx, q, EventTypeTransferCompleted, result.Transfer.ID, result.Transfer)
+}
+
+// lockAccounts takes a row lock on both accounts, always locking the smaller ID first,
+// and returns them in the order they were asked for.
+func lockAccounts(ctx context.Context, q Querier, fromAccountID, toAccountID int64) (fromAccount Account, toAccount Account, err error) {
+	accounts, err := lockAccountSet(ctx, q, fromAccountID, toAccountID)
+	if err != nil {
+		return
+	}
+	return accounts[fromAccountID], accounts[toAccountID], nil
+}
+
+func addMoney(ctx context.Context, q Querier, arg AddMoneyParams) (account1 Account, account2 Account, err error) {
+	account1, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     arg.accountID1,
+		Amount: arg.amount1,
+	})
+
+	if err != nil {
+		return
+	}
+
+	account2, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:     arg.accountID2,
+		Amount: arg.amount2,
+	})
+	if err != nil {
+		return
+	}
+
+	return
+
+}
@@ -0,0 +1,359 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"simplebank/db/utils"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// forEachStore runs test against every Store implementation, so that they all
+// have to pass the same suite. The "sql" store needs the test database from TestMain.
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	stores := []struct {
+		name     string
+		newStore func() Store
+	}{
+		{name: "sql", newStore: func() Store { return NewStore(testDB) }},
+		{name: "memory", newStore: NewMemoryStore},
+	}
+
+	for _, s := range stores {
+		s := s
+		t.Run(s.name, func(t *testing.T) {
+			test(t, s.newStore())
+		})
+	}
+}
+
+func TestConformanceAccounts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		account := createFundedAccount(t, store, utils.RandomCurrency(), utils.RandomMoney())
+		require.NotZero(t, account.ID)
+		require.NotZero(t, account.CreatedAt)
+
+		got, err := store.GetAccount(ctx, account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.ID, got.ID)
+		require.Equal(t, account.Owner, got.Owner)
+		require.Equal(t, account.Balance, got.Balance)
+		require.Equal(t, account.Currency, got.Currency)
+
+		updated, err := store.UpdateAccount(ctx, UpdateAccountParams{ID: account.ID, Balance: 42})
+		require.NoError(t, err)
+		require.Equal(t, int64(42), updated.Balance)
+
+		updated, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{ID: account.ID, Amount: -2})
+		require.NoError(t, err)
+		require.Equal(t, int64(40), updated.Balance)
+
+		deleted, err := store.DeleteAccount(ctx, account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.ID, deleted.ID)
+
+		_, err = store.GetAccount(ctx, account.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.UpdateAccount(ctx, UpdateAccountParams{ID: account.ID, Balance: 1})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.AddAccountBalance(ctx, AddAccountBalanceParams{ID: account.ID, Amount: 1})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		_, err = store.DeleteAccount(ctx, account.ID)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	})
+}
+
+func TestConformanceListAccounts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		for i := 0; i < 10; i++ {
+			createFundedAccount(t, store, utils.RandomCurrency(), utils.RandomMoney())
+		}
+
+		firstPage, err := store.ListAccounts(ctx, ListAccountsParams{Limit: 5, Offset: 0})
+		require.NoError(t, err)
+		require.Len(t, firstPage, 5)
+
+		secondPage, err := store.ListAccounts(ctx, ListAccountsParams{Limit: 5, Offset: 5})
+		require.NoError(t, err)
+		require.Len(t, secondPage, 5)
+
+		// pages are ordered by id and do not overlap
+		page := append(firstPage, secondPage...)
+		for i := 1; i < len(page); i++ {
+			require.Less(t, page[i-1].ID, page[i].ID)
+		}
+
+		empty, err := store.ListAccounts(ctx, ListAccountsParams{Limit: 0, Offset: 0})
+		require.NoError(t, err)
+		require.NotNil(t, empty)
+		require.Empty(t, empty)
+	})
+}
+
+func TestConformanceEntries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		account := createFundedAccount(t, store, utils.RandomCurrency(), utils.RandomMoney())
+		for i := 0; i < 5; i++ {
+			_, err := store.CreateEntry(ctx, CreateEntryParams{AccountID: account.ID, Amount: int64(i + 1), Type: EntryTypeAdjustment})
+			require.NoError(t, err)
+		}
+
+		entries, err := store.ListEntriesByAccount(ctx, ListEntriesByAccountParams{
+			AccountID: account.ID,
+			Limit:     3,
+			Offset:    1,
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		for i, entry := range entries {
+			require.Equal(t, account.ID, entry.AccountID)
+			require.Equal(t, int64(i+2), entry.Amount)
+		}
+
+		got, err := store.GetEntry(ctx, entries[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, entries[0].ID, got.ID)
+		require.Equal(t, entries[0].Amount, got.Amount)
+
+		_, err = store.GetEntry(ctx, -1)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = store.CreateEntry(ctx, CreateEntryParams{AccountID: -1, Amount: 10, Type: EntryTypeAdjustment})
+		require.ErrorIs(t, err, ErrForeignKeyViolation)
+
+		_, err = store.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  account.ID,
+			Amount:     10,
+			Type:       EntryTypeTransfer,
+			TransferID: sql.NullInt64{Int64: -1, Valid: true},
+		})
+		require.ErrorIs(t, err, ErrForeignKeyViolation)
+
+		_, err = store.CreateEntry(ctx, CreateEntryParams{AccountID: account.ID, Amount: 10, Type: "refund"})
+		require.Equal(t, InvalidTextRepresentation, ErrorCode(err))
+
+		legs, err := store.ListEntriesByTransfer(ctx, -1)
+		require.NoError(t, err)
+		require.Empty(t, legs)
+
+		// an account with entries cannot be deleted
+		_, err = store.DeleteAccount(ctx, account.ID)
+		require.ErrorIs(t, err, ErrAccountHasHistory)
+	})
+}
+
+func TestConformanceTransfers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		account1 := createFundedAccount(t, store, utils.RandomCurrency(), utils.RandomMoney())
+		account2 := createFundedAccount(t, store, utils.RandomCurrency(), utils.RandomMoney())
+
+		for i := 0; i < 3; i++ {
+			_, err := store.CreateTransfer(ctx, CreateTransferParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        10,
+				Status:        TransferStatusPosted,
+			})
+			require.NoError(t, err)
+		}
+		transfer, err := store.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: account2.ID,
+			ToAccountID:   account1.ID,
+			Amount:        10,
+			Status:        TransferStatusPosted,
+		})
+		require.NoError(t, err)
+
+		got, err := store.GetTransfer(ctx, transfer.ID)
+		require.NoError(t, err)
+		require.Equal(t, transfer.ID, got.ID)
+		require.Equal(t, transfer.FromAccountID, got.FromAccountID)
+		require.Equal(t, transfer.ToAccountID, got.ToAccountID)
+
+		_, err = store.GetTransfer(ctx, -1)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		from, err := store.ListTransfersFromAccount(ctx, ListTransfersFromAccountParams{
+			FromAccountID: account1.ID,
+			Limit:         10,
+		})
+		require.NoError(t, err)
+		require.Len(t, from, 3)
+
+		to, err := store.ListTransfersToAccount(ctx, ListTransfersToAccountParams{
+			ToAccountID: account1.ID,
+			Limit:       10,
+		})
+		require.NoError(t, err)
+		require.Len(t, to, 1)
+		require.Equal(t, transfer.ID, to[0].ID)
+
+		all, err := store.ListTransfers(ctx, ListTransfersParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account1.ID,
+			Limit:         10,
+		})
+		require.NoError(t, err)
+		require.Len(t, all, 4)
+		require.Equal(t, transfer.ID, all[3].ID)
+
+		_, err = store.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   -1,
+			Amount:        10,
+			Status:        TransferStatusPosted,
+		})
+		require.ErrorIs(t, err, ErrForeignKeyViolation)
+	})
+}
+
+func TestConformanceUsers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		user := createRandomUserIn(t, store)
+
+		got, err := store.GetUser(ctx, user.Username)
+		require.NoError(t, err)
+		require.Equal(t, user.Username, got.Username)
+		require.Equal(t, user.Email, got.Email)
+
+		_, err = store.GetUser(ctx, utils.RandomOwner())
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		_, err = store.CreateUser(ctx, CreateUserParams{
+			Username:       user.Username,
+			HashedPassword: user.HashedPassword,
+			FullName:       user.FullName,
+			Email:          utils.RandomEmail(),
+		})
+		require.ErrorIs(t, err, ErrUniqueViolation)
+
+		_, err = store.CreateUser(ctx, CreateUserParams{
+			Username:       utils.RandomOwner(),
+			HashedPassword: user.HashedPassword,
+			FullName:       user.FullName,
+			Email:          user.Email,
+		})
+		require.ErrorIs(t, err, ErrUniqueViolation)
+	})
+}
+
+func TestConformanceAccountOwner(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		_, err := store.CreateAccount(ctx, CreateAccountParams{
+			Owner:    utils.RandomOwner(),
+			Currency: utils.USD,
+		})
+		require.ErrorIs(t, err, ErrForeignKeyViolation)
+
+		var constraintErr *ConstraintError
+		require.ErrorAs(t, err, &constraintErr)
+		require.Equal(t, "accounts_owner_fkey", constraintErr.Constraint)
+
+		user := createRandomUserIn(t, store)
+		_, err = store.CreateAccount(ctx, CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.USD,
+		})
+		require.NoError(t, err)
+
+		_, err = store.CreateAccount(ctx, CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.USD,
+		})
+		require.ErrorIs(t, err, ErrUniqueViolation)
+		require.Equal(t, UniqueViolation, ErrorCode(err))
+
+		_, err = store.CreateAccount(ctx, CreateAccountParams{
+			Owner:    user.Username,
+			Currency: utils.EUR,
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestConformanceListAccountsByOwner(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		ctx := context.Background()
+
+		user := createRandomUserIn(t, store)
+		for _, currency := range []string{utils.USD, utils.EUR, utils.CAD} {
+			_, err := store.CreateAccount(ctx, CreateAccountParams{
+				Owner:    user.Username,
+				Currency: currency,
+			})
+			require.NoError(t, err)
+		}
+		createFundedAccount(t, store, utils.USD, utils.RandomMoney())
+
+		accounts, err := store.ListAccountsByOwner(ctx, ListAccountsByOwnerParams{
+			Owner: user.Username,
+			Limit: 5,
+		})
+		require.NoError(t, err)
+		require.Len(t, accounts, 3)
+		for i, account := range accounts {
+			require.Equal(t, user.Username, account.Owner)
+			if i > 0 {
+				require.Less(t, accounts[i-1].ID, account.ID)
+			}
+		}
+
+		accounts, err = store.ListAccountsByOwner(ctx, ListAccountsByOwnerParams{
+			Owner:  user.Username,
+			Limit:  5,
+			Offset: 2,
+		})
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+
+		accounts, err = store.ListAccountsByOwner(ctx, ListAccountsByOwnerParams{
+			Owner: utils.RandomOwner(),
+			Limit: 5,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, accounts)
+		require.Empty(t, accounts)
+	})
+}
+
+func TestMemoryStoreRollback(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore().(*MemoryStore)
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 100)
+	user := createRandomUserIn(t, store)
+
+	errRollback := errors.New("rollback")
+	err := store.execTx(ctx, nil, func(q Querier) error {
+		_, err := q.UpdateAccount(ctx, UpdateAccountParams{ID: account.ID, Balance: 500})
+		require.NoError(t, err)
+		_, err = q.CreateEntry(ctx, CreateEntryParams{AccountID: account.ID, Amount: 400, Type: EntryTypeAdjustment})
+		require.NoError(t, err)
+		_, err = q.CreateAccount(ctx, CreateAccountParams{Owner: user.Username, Currency: account.Currency})
+		require.NoError(t, err)
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	// every change of the failed transaction is undone
+	requireBalance(t, store, account.ID, 100)
+	entries, err := store.ListEntriesByAccount(ctx, ListEntriesByAccountParams{AccountID: account.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	accounts, err := store.ListAccountsByOwner(ctx, ListAccountsByOwnerParams{Owner: user.Username, Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+}
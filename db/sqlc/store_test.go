@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"simplebank/db/utils"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -10,11 +12,14 @@ import (
 
 
 func TestTransferTx(t *testing.T) {
-	store := NewStore(testDB)
+	forEachStore(t, testTransferTx)
+}
 
+func testTransferTx(t *testing.T, store Store) {
 	// Create test accounts
-	fromAccount := createRandomAccount(t)
-	toAccount := createRandomAccount(t)
+	currency := utils.RandomCurrency()
+	fromAccount := createFundedAccount(t, store, currency, 1000)
+	toAccount := createFundedAccount(t, store, currency, 1000)
 
 
 	// Run n concurrent transfer transactions
@@ -29,7 +34,7 @@ func TestTransferTx(t *testing.T) {
 			result, err := store.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: fromAccount.ID,
 				ToAccountID:   toAccount.ID,
-				Amount:        amount,
+				Amount:        Amount(amount),
 			})
 
 			errs <- err
@@ -67,6 +72,8 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, fromEntry.Amount, -amount)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
+		require.Equal(t, EntryTypeTransfer, fromEntry.Type)
+		require.Equal(t, sql.NullInt64{Int64: transfer.ID, Valid: true}, fromEntry.TransferID)
 
 		// get entry from db to check it was created
 		fromEntryFromDB, err := store.GetEntry(context.Background(), fromEntry.ID)
@@ -79,6 +86,13 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, toEntry.Amount, amount)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
+		require.Equal(t, EntryTypeTransfer, toEntry.Type)
+		require.Equal(t, sql.NullInt64{Int64: transfer.ID, Valid: true}, toEntry.TransferID)
+
+		// both legs can be fetched from the transfer
+		legs, err := store.ListEntriesByTransfer(context.Background(), transfer.ID)
+		require.NoError(t, err)
+		require.Equal(t, []Entry{fromEntry, toEntry}, legs)
 
 		// get entry from db to check it was created
 		toEntryFromDB, err := store.GetEntry(context.Background(), toEntry.ID)
@@ -109,11 +123,11 @@ func TestTransferTx(t *testing.T) {
 	}
 
 	// check the final updated account balances
-	updatedAccount1, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	updatedAccount1, err := store.GetAccount(context.Background(), fromAccount.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, updatedAccount1)
 
-	updatedAccount2, err := testQueries.GetAccount(context.Background(), toAccount.ID)
+	updatedAccount2, err := store.GetAccount(context.Background(), toAccount.ID)
 	require.NoError(t, err)
 	require.NotEmpty(t, updatedAccount2)
 
@@ -123,11 +137,13 @@ func TestTransferTx(t *testing.T) {
 }
 
 func TestTransferTxDeadlock(t *testing.T) {
+	forEachStore(t, testTransferTxDeadlock)
+}
 
-	store := NewStore(testDB)
-
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+func testTransferTxDeadlock(t *testing.T, store Store) {
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 1000)
+	account2 := createFundedAccount(t, store, currency, 1000)
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
 	n := 10
@@ -147,7 +163,7 @@ func TestTransferTxDeadlock(t *testing.T) {
 			_, err := store.TransferTx(context.Background(), TransferTxParams{
 				FromAccountID: fromAccountID,
 				ToAccountID:   toAccountID,
-				Amount:        amount,
+				Amount:        Amount(amount),
 			})
 
 			errs <- err
@@ -169,4 +185,288 @@ func TestTransferTxDeadlock(t *testing.T) {
 	fmt.Println(">> after:", updatedAccount1.Balance, updatedAccount2.Balance)
 	// require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	// require.Equal(t, account2.Balance, updatedAccount2.Balance)
-}
\ No newline at end of file
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T) {
+	forEachStore(t, testTransferTxCurrencyMismatch)
+}
+
+func testTransferTxCurrencyMismatch(t *testing.T, store Store) {
+	fromAccount := createFundedAccount(t, store, "USD", 1000)
+	toAccount := createFundedAccount(t, store, "EUR", 1000)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+	require.Empty(t, result)
+
+	requireBalance(t, store, fromAccount.ID, fromAccount.Balance)
+	requireBalance(t, store, toAccount.ID, toAccount.Balance)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	forEachStore(t, testTransferTxInsufficientFunds)
+}
+
+func testTransferTxInsufficientFunds(t *testing.T, store Store) {
+	currency := utils.RandomCurrency()
+	fromAccount := createFundedAccount(t, store, currency, 5)
+	toAccount := createFundedAccount(t, store, currency, 1000)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Empty(t, result)
+
+	requireBalance(t, store, fromAccount.ID, fromAccount.Balance)
+	requireBalance(t, store, toAccount.ID, toAccount.Balance)
+
+	// no transfer should have been left behind
+	transfers, err := store.ListTransfersFromAccount(context.Background(), ListTransfersFromAccountParams{
+		FromAccountID: fromAccount.ID,
+		Limit:         10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestTransferTxAccountNotFound(t *testing.T) {
+	forEachStore(t, testTransferTxAccountNotFound)
+}
+
+func testTransferTxAccountNotFound(t *testing.T, store Store) {
+	account := createFundedAccount(t, store, utils.RandomCurrency(), utils.RandomMoney())
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   -1,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	requireBalance(t, store, account.ID, account.Balance)
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	forEachStore(t, testTransferTxIdempotencyKey)
+}
+
+func testTransferTxIdempotencyKey(t *testing.T, store Store) {
+	currency := utils.RandomCurrency()
+	fromAccount := createFundedAccount(t, store, currency, 1000)
+	toAccount := createFundedAccount(t, store, currency, 1000)
+
+	arg := TransferTxParams{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         10,
+		IdempotencyKey: utils.RandomString(16),
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// a replay returns the original result and does not move the money again
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromEntry.ID, result2.FromEntry.ID)
+	require.Equal(t, result1.ToEntry.ID, result2.ToEntry.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+	require.Equal(t, result1.ToAccount.Balance, result2.ToAccount.Balance)
+
+	requireBalance(t, store, fromAccount.ID, fromAccount.Balance-10)
+	requireBalance(t, store, toAccount.ID, toAccount.Balance+10)
+
+	// the same key with different parameters is rejected
+	conflict := arg
+	conflict.Amount = 20
+	_, err = store.TransferTx(context.Background(), conflict)
+	require.Equal(t, ErrIdempotencyKeyConflict, err)
+
+	requireBalance(t, store, fromAccount.ID, fromAccount.Balance-10)
+	requireBalance(t, store, toAccount.ID, toAccount.Balance+10)
+
+	// keys are scoped to the source account, so another account can use the same key
+	otherAccount := createFundedAccount(t, store, currency, 1000)
+	other := arg
+	other.FromAccountID = otherAccount.ID
+	other.Amount = 20
+	result3, err := store.TransferTx(context.Background(), other)
+	require.NoError(t, err)
+	require.NotEqual(t, result1.Transfer.ID, result3.Transfer.ID)
+
+	requireBalance(t, store, otherAccount.ID, otherAccount.Balance-20)
+	requireBalance(t, store, toAccount.ID, toAccount.Balance+30)
+}
+
+func TestTransferTxIdempotencyKeyConcurrent(t *testing.T) {
+	forEachStore(t, testTransferTxIdempotencyKeyConcurrent)
+}
+
+func testTransferTxIdempotencyKeyConcurrent(t *testing.T, store Store) {
+	currency := utils.RandomCurrency()
+	fromAccount := createFundedAccount(t, store, currency, 1000)
+	toAccount := createFundedAccount(t, store, currency, 1000)
+
+	arg := TransferTxParams{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         10,
+		IdempotencyKey: utils.RandomString(16),
+	}
+
+	n := 5
+	errs := make(chan error)
+	results := make(chan TransferTxResult)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.TransferTx(context.Background(), arg)
+
+			errs <- err
+			results <- result
+		}()
+	}
+
+	transferIDs := make(map[int64]bool)
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+
+		result := <-results
+		transferIDs[result.Transfer.ID] = true
+	}
+
+	// every submission got the same transfer, and the money moved only once
+	require.Len(t, transferIDs, 1)
+	requireBalance(t, store, fromAccount.ID, fromAccount.Balance-10)
+	requireBalance(t, store, toAccount.ID, toAccount.Balance+10)
+}
+
+// createFundedAccount creates an account with the given currency and balance.
+func createFundedAccount(t *testing.T, q Querier, currency string, balance int64) Account {
+	account, err := q.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUserIn(t, q).Username,
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func requireBalance(t *testing.T, q Querier, accountID int64, balance int64) {
+	account, err := q.GetAccount(context.Background(), accountID)
+	require.NoError(t, err)
+	require.Equal(t, balance, account.Balance)
+}
+
+func TestDepositTx(t *testing.T) {
+	forEachStore(t, testDepositTx)
+}
+
+func testDepositTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 0)
+
+	result, err := store.DepositTx(ctx, DepositTxParams{AccountID: account.ID, Amount: 100, ExternalRef: "card-42"})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(100), result.Entry.Amount)
+	require.Equal(t, EntryTypeDeposit, result.Entry.Type)
+	require.Equal(t, sql.NullString{String: "card-42", Valid: true}, result.Entry.ExternalRef)
+	require.False(t, result.Entry.TransferID.Valid)
+
+	entry, err := store.GetEntry(ctx, result.Entry.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Entry, entry)
+	requireBalance(t, store, account.ID, 100)
+
+	// the reference is optional
+	result, err = store.DepositTx(ctx, DepositTxParams{AccountID: account.ID, Amount: 1})
+	require.NoError(t, err)
+	require.False(t, result.Entry.ExternalRef.Valid)
+	requireBalance(t, store, account.ID, 101)
+
+	for _, amount := range []Amount{0, -10} {
+		_, err = store.DepositTx(ctx, DepositTxParams{AccountID: account.ID, Amount: amount})
+		require.ErrorIs(t, err, ErrInvalidAmount)
+	}
+
+	_, err = store.DepositTx(ctx, DepositTxParams{AccountID: -1, Amount: 10})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	requireBalance(t, store, account.ID, 101)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	forEachStore(t, testWithdrawTx)
+}
+
+func testWithdrawTx(t *testing.T, store Store) {
+	ctx := context.Background()
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 0)
+	_, err := store.DepositTx(ctx, DepositTxParams{AccountID: account.ID, Amount: 100})
+	require.NoError(t, err)
+
+	result, err := store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account.ID, Amount: 60, ExternalRef: "payout-7"})
+	require.NoError(t, err)
+	require.Equal(t, int64(40), result.Account.Balance)
+	require.Equal(t, int64(-60), result.Entry.Amount)
+	require.Equal(t, EntryTypeWithdrawal, result.Entry.Type)
+	require.Equal(t, sql.NullString{String: "payout-7", Valid: true}, result.Entry.ExternalRef)
+
+	// the balance never goes below zero, and a refused withdrawal writes nothing
+	_, err = store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account.ID, Amount: 41})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	requireBalance(t, store, account.ID, 40)
+
+	entries, err := store.ListEntriesByAccount(ctx, ListEntriesByAccountParams{AccountID: account.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	_, err = store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account.ID, Amount: 40})
+	require.NoError(t, err)
+	requireBalance(t, store, account.ID, 0)
+
+	_, err = store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account.ID, Amount: 0})
+	require.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestWithdrawTxConcurrent(t *testing.T) {
+	forEachStore(t, testWithdrawTxConcurrent)
+}
+
+func testWithdrawTxConcurrent(t *testing.T, store Store) {
+	ctx := context.Background()
+	account := createFundedAccount(t, store, utils.RandomCurrency(), 0)
+	_, err := store.DepositTx(ctx, DepositTxParams{AccountID: account.ID, Amount: 50})
+	require.NoError(t, err)
+
+	// only five of the ten withdrawals fit in the balance
+	n := 10
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.WithdrawTx(ctx, WithdrawTxParams{AccountID: account.ID, Amount: 10})
+			errs <- err
+		}()
+	}
+
+	failed := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err != nil {
+			require.ErrorIs(t, err, ErrInsufficientFunds)
+			failed++
+		}
+	}
+	require.Equal(t, 5, failed)
+	requireBalance(t, store, account.ID, 0)
+}
@@ -7,22 +7,82 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.Reason,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.ToAmount,
+		&i.ExchangeRate,
+	)
+	return i, err
+}
+
+const countPendingTransfersByAccount = `-- name: CountPendingTransfersByAccount :one
+SELECT count(*) FROM transfers
+WHERE status = 'pending'
+  AND (from_account_id = $1 OR to_account_id = $1)
+`
+
+func (q *Queries) CountPendingTransfersByAccount(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPendingTransfersByAccount, accountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
-INSERT INTO transfers (from_account_id, to_account_id, amount, created_at) 
-VALUES ($1, $2, $3, NOW())
-RETURNING id, from_account_id, to_account_id, amount, created_at
+INSERT INTO transfers (from_account_id, to_account_id, amount, created_at, reversal_of, reason, status, to_amount, exchange_rate)
+VALUES ($1, $2, $3, NOW(), $4, $5, $6, $7, $8)
+RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64          `json:"from_account_id"`
+	ToAccountID   int64          `json:"to_account_id"`
+	Amount        int64          `json:"amount"`
+	ReversalOf    sql.NullInt64  `json:"reversal_of"`
+	Reason        sql.NullString `json:"reason"`
+	Status        TransferStatus `json:"status"`
+	ToAmount      sql.NullInt64  `json:"to_amount"`
+	ExchangeRate  sql.NullString `json:"exchange_rate"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversalOf,
+		arg.Reason,
+		arg.Status,
+		arg.ToAmount,
+		arg.ExchangeRate,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -30,12 +90,18 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.Reason,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.ToAmount,
+		&i.ExchangeRate,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers WHERE id = $1
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers WHERE id = $1
 `
 
 func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
@@ -47,12 +113,84 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.Reason,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.ToAmount,
+		&i.ExchangeRate,
 	)
 	return i, err
 }
 
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.Reason,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.ToAmount,
+		&i.ExchangeRate,
+	)
+	return i, err
+}
+
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers
+WHERE reversal_of = $1::bigint
+ORDER BY id
+`
+
+func (q *Queries) ListTransferReversals(ctx context.Context, transferID int64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferReversals, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.Reason,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.ToAmount,
+			&i.ExchangeRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers
 WHERE 
     from_account_id = $1 OR
     to_account_id = $2
@@ -88,6 +226,12 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.Reason,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.ToAmount,
+			&i.ExchangeRate,
 		); err != nil {
 			return nil, err
 		}
@@ -103,7 +247,7 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 }
 
 const listTransfersFromAccount = `-- name: ListTransfersFromAccount :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers 
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers 
 WHERE from_account_id = $1
 ORDER BY id
 LIMIT $2 OFFSET $3
@@ -130,6 +274,124 @@ func (q *Queries) ListTransfersFromAccount(ctx context.Context, arg ListTransfer
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.Reason,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.ToAmount,
+			&i.ExchangeRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersFromAccountPage = `-- name: ListTransfersFromAccountPage :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers
+WHERE from_account_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListTransfersFromAccountPageParams struct {
+	FromAccountID  int64     `json:"from_account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	PageSize       int32     `json:"page_size"`
+}
+
+func (q *Queries) ListTransfersFromAccountPage(ctx context.Context, arg ListTransfersFromAccountPageParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersFromAccountPage,
+		arg.FromAccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.Reason,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.ToAmount,
+			&i.ExchangeRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersPage = `-- name: ListTransfersPage :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $2)
+  AND (created_at, id) > ($3::timestamptz, $4::bigint)
+ORDER BY created_at, id
+LIMIT $5
+`
+
+type ListTransfersPageParams struct {
+	FromAccountID  int64     `json:"from_account_id"`
+	ToAccountID    int64     `json:"to_account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	PageSize       int32     `json:"page_size"`
+}
+
+func (q *Queries) ListTransfersPage(ctx context.Context, arg ListTransfersPageParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersPage,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.Reason,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.ToAmount,
+			&i.ExchangeRate,
 		); err != nil {
 			return nil, err
 		}
@@ -145,7 +407,7 @@ func (q *Queries) ListTransfersFromAccount(ctx context.Context, arg ListTransfer
 }
 
 const listTransfersToAccount = `-- name: ListTransfersToAccount :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers 
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers 
 WHERE to_account_id = $1
 ORDER BY id
 LIMIT $2 OFFSET $3
@@ -172,6 +434,67 @@ func (q *Queries) ListTransfersToAccount(ctx context.Context, arg ListTransfersT
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.Reason,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.ToAmount,
+			&i.ExchangeRate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersToAccountPage = `-- name: ListTransfersToAccountPage :many
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate FROM transfers
+WHERE to_account_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListTransfersToAccountPageParams struct {
+	ToAccountID    int64     `json:"to_account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+	PageSize       int32     `json:"page_size"`
+}
+
+func (q *Queries) ListTransfersToAccountPage(ctx context.Context, arg ListTransfersToAccountPageParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersToAccountPage,
+		arg.ToAccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ReversalOf,
+			&i.Reason,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.ToAmount,
+			&i.ExchangeRate,
 		); err != nil {
 			return nil, err
 		}
@@ -185,3 +508,34 @@ func (q *Queries) ListTransfersToAccount(ctx context.Context, arg ListTransfersT
 	}
 	return items, nil
 }
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of, reason, reversed_amount, status, to_amount, exchange_rate
+`
+
+type UpdateTransferStatusParams struct {
+	Status TransferStatus `json:"status"`
+	ID     int64          `json:"id"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.Status, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOf,
+		&i.Reason,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.ToAmount,
+		&i.ExchangeRate,
+	)
+	return i, err
+}
@@ -10,7 +10,7 @@ import (
 
 func TestCreateTransfer(t *testing.T) {
     CreateAccountParams := CreateAccountParams{
-        Owner:    utils.RandomOwner(),
+        Owner:    createRandomUser(t).Username,
         Balance:  utils.RandomMoney(),
         Currency: utils.RandomCurrency(),
     }
@@ -19,7 +19,8 @@ func TestCreateTransfer(t *testing.T) {
     account1, err := testQueries.CreateAccount(context.Background(), CreateAccountParams)
     require.NoError(t, err)
 
-    // Create a test account
+    // Create a test account for another owner
+    CreateAccountParams.Owner = createRandomUser(t).Username
     account2, err := testQueries.CreateAccount(context.Background(), CreateAccountParams)
     require.NoError(t, err)
 
@@ -28,6 +29,7 @@ func TestCreateTransfer(t *testing.T) {
         FromAccountID: account1.ID,
         ToAccountID:   account2.ID,
         Amount:        utils.RandomMoney(),
+        Status:        TransferStatusPosted,
     }
 
     // Call the CreateTransfer method
@@ -45,6 +47,7 @@ func TestCreateTransfer(t *testing.T) {
         ToAccountID:   params.ToAccountID,
         Amount:       params.Amount,
         CreatedAt:    transfer.CreatedAt,
+        Status:       params.Status,
     }
 
     // Compare the result with the expected value
@@ -54,7 +57,7 @@ func TestCreateTransfer(t *testing.T) {
 func TestGetTransfer(t *testing.T) {
 	// Create a test account
 	CreateAccountParams := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -66,6 +69,7 @@ func TestGetTransfer(t *testing.T) {
 		FromAccountID: account.ID,
 		ToAccountID:   account.ID,
 		Amount:        utils.RandomMoney(),
+		Status:        TransferStatusPosted,
 	}
 	transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams)
 	require.NoError(t, err)
@@ -90,7 +94,7 @@ func TestGetTransfer_failure(t *testing.T) {
 func TestListTransfers(t *testing.T) {
 	// Create a test account
 	CreateAccountParams := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -102,6 +106,7 @@ func TestListTransfers(t *testing.T) {
 		FromAccountID: account.ID,
 		ToAccountID:   account.ID,
 		Amount:        utils.RandomMoney(),
+		Status:        TransferStatusPosted,
 	}
 	_, err = testQueries.CreateTransfer(context.Background(), CreateTransferParams)
 	require.NoError(t, err)
@@ -134,7 +139,7 @@ func TestListTransfers(t *testing.T) {
 func TestListTransfersFromAccount(t *testing.T) {
 	// Create a test account
 	CreateAccountParams := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -146,6 +151,7 @@ func TestListTransfersFromAccount(t *testing.T) {
 		FromAccountID: account.ID,
 		ToAccountID:   account.ID,
 		Amount:        utils.RandomMoney(),
+		Status:        TransferStatusPosted,
 	}
 	_, err = testQueries.CreateTransfer(context.Background(), CreateTransferParams)
 	require.NoError(t, err)
@@ -177,7 +183,7 @@ func TestListTransfersFromAccount(t *testing.T) {
 func TestListTransfersToAccount(t *testing.T) {
 	// Create a test account
 	CreateAccountParams := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    createRandomUser(t).Username,
 		Balance:  utils.RandomMoney(),
 		Currency: utils.RandomCurrency(),
 	}
@@ -189,6 +195,7 @@ func TestListTransfersToAccount(t *testing.T) {
 		FromAccountID: account.ID,
 		ToAccountID:   account.ID,
 		Amount:        utils.RandomMoney(),
+		Status:        TransferStatusPosted,
 	}
 	_, err = testQueries.CreateTransfer(context.Background(), CreateTransferParams)
 	require.NoError(t, err)
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config stores all configuration of the application.
+// The values are read by viper from a config file or environment variables.
+type Config struct {
+	DBDriver            string        `mapstructure:"DB_DRIVER"`
+	DBSource            string        `mapstructure:"DB_SOURCE"`
+	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
+	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	// HoldExpiryInterval is how often expired holds are released. Zero disables the sweep.
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	// OutboxPollInterval is how often the outbox relay looks for new events. Zero disables the relay.
+	OutboxPollInterval time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
+	// GRPCServerAddress is where the gRPC server listens. Empty disables it, and the gateway with it.
+	GRPCServerAddress string `mapstructure:"GRPC_SERVER_ADDRESS"`
+	// GatewayServerAddress is where the HTTP/JSON gateway to the gRPC server listens. Empty disables it.
+	GatewayServerAddress string `mapstructure:"GATEWAY_SERVER_ADDRESS"`
+}
+
+// LoadConfig reads configuration from file or environment variables.
+func LoadConfig(path string) (config Config, err error) {
+	viper.AddConfigPath(path)
+	viper.SetConfigName("app")
+	viper.SetConfigType("env")
+
+	viper.AutomaticEnv()
+
+	err = viper.ReadInConfig()
+	if err != nil {
+		return
+	}
+
+	err = viper.Unmarshal(&config)
+	return
+}
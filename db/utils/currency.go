@@ -0,0 +1,17 @@
+package utils
+
+import "simplebank/currency"
+
+// Constants for the currencies used most often. Package currency lists every supported one.
+const (
+	USD = "USD"
+	EUR = "EUR"
+	CAD = "CAD"
+	GBP = "GBP"
+	CHF = "CHF"
+)
+
+// IsSupportedCurrency returns true if the currency is supported
+func IsSupportedCurrency(code string) bool {
+	return currency.IsSupported(code)
+}
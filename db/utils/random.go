@@ -30,11 +30,15 @@ func RandomOwner() string {
 }
 
 func RandomMoney() int64 {
-	return int64(RandomInt(0, 1000))
+	return int64(RandomInt(1, 1000))
 }
 
 func RandomCurrency() string {
-	currencies := []string{"USD", "EUR", "CAD"}
+	currencies := []string{USD, EUR, CAD, GBP, CHF}
 	n := len(currencies)
 	return currencies[r.Intn(n)]
-}
\ No newline at end of file
+}
+
+func RandomEmail() string {
+	return RandomString(6) + "@email.com"
+}
@@ -0,0 +1,78 @@
+// Package fx prices cross-currency transfers.
+// A RateProvider turns a currency pair and an amount into a Quote.
+// The store asks its own provider for the quote of each exchange, so callers never supply a rate.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"simplebank/currency"
+)
+
+var (
+	// ErrUnsupportedPair is returned when a provider has no rate for a currency pair.
+	ErrUnsupportedPair = errors.New("unsupported currency pair")
+	// ErrQuoteExpired is returned when a quote is used after its expiry.
+	ErrQuoteExpired = errors.New("fx quote expired")
+	// ErrInvalidQuote is returned when a quote does not hold positive amounts and a valid rate,
+	// or when its target amount is not its source amount converted at its rate.
+	ErrInvalidQuote = errors.New("invalid fx quote")
+)
+
+// Quote is an offer to exchange SourceAmount of SourceCurrency for TargetAmount of TargetCurrency,
+// valid until ExpiresAt. Amounts are in the minor units of their currency.
+type Quote struct {
+	SourceCurrency string `json:"source_currency"`
+	TargetCurrency string `json:"target_currency"`
+	// Rate is the number of TargetCurrency units one SourceCurrency unit buys, in major units: 150 for USD/JPY.
+	Rate         Rate      `json:"rate"`
+	SourceAmount int64     `json:"source_amount"`
+	TargetAmount int64     `json:"target_amount"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the quote can no longer be used at now.
+func (quote Quote) Expired(now time.Time) bool {
+	return !now.Before(quote.ExpiresAt)
+}
+
+// Validate checks that the quote can be executed at now.
+// Quotes are plain values that callers may build or alter, so the target amount is recomputed from the
+// source amount and the rate, and a quote that would credit more or less than that is rejected.
+func (quote Quote) Validate(now time.Time) error {
+	if quote.SourceAmount <= 0 || quote.TargetAmount <= 0 || !quote.Rate.IsValid() {
+		return ErrInvalidQuote
+	}
+
+	source, err := currency.Lookup(quote.SourceCurrency)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuote, err)
+	}
+	target, err := currency.Lookup(quote.TargetCurrency)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidQuote, err)
+	}
+	converted, err := Convert(quote.SourceAmount, quote.Rate, source, target)
+	if err != nil {
+		return err
+	}
+	if converted != quote.TargetAmount {
+		return fmt.Errorf("%w: %d %s at %s is %d %s, not %d",
+			ErrInvalidQuote, quote.SourceAmount, quote.SourceCurrency, quote.Rate, converted, quote.TargetCurrency, quote.TargetAmount)
+	}
+
+	if quote.Expired(now) {
+		return ErrQuoteExpired
+	}
+	return nil
+}
+
+// RateProvider quotes exchange rates. The store calls Quote while it holds the locks of the accounts
+// of the exchange, so implementations should answer from rates they already have rather than a slow external call.
+type RateProvider interface {
+	// Quote prices the exchange of sourceAmount of the source currency into the target currency.
+	Quote(ctx context.Context, source, target string, sourceAmount int64) (Quote, error)
+}
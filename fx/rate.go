@@ -0,0 +1,118 @@
+package fx
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"simplebank/currency"
+)
+
+// Rate is an exact exchange rate: the number of target currency units one source currency unit buys,
+// in major units. It is kept as a fraction, so that converting an amount rounds once, at the end.
+// The zero Rate is not valid.
+type Rate struct {
+	rat *big.Rat
+}
+
+// ParseRate parses a positive rate written as a decimal, like "0.92", or as a fraction, like "25/23".
+func ParseRate(s string) (Rate, error) {
+	rat, ok := new(big.Rat).SetString(s)
+	if !ok || rat.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q", s)
+	}
+	return Rate{rat: rat}, nil
+}
+
+// IsValid reports whether the rate is positive.
+func (rate Rate) IsValid() bool {
+	return rate.rat != nil && rate.rat.Sign() > 0
+}
+
+// Inverse returns the rate of the opposite currency pair. The rate must be valid.
+func (rate Rate) Inverse() Rate {
+	return Rate{rat: new(big.Rat).Inv(rate.rat)}
+}
+
+// String writes the rate exactly: as a decimal when it has a finite decimal expansion, as a fraction otherwise.
+func (rate Rate) String() string {
+	if rate.rat == nil {
+		return "0"
+	}
+	if places, ok := decimalPlaces(rate.rat.Denom()); ok {
+		return rate.rat.FloatString(places)
+	}
+	return rate.rat.String()
+}
+
+// Decimal writes the rate as a decimal rounded to at most places digits after the point.
+func (rate Rate) Decimal(places int) string {
+	if rate.rat == nil {
+		return "0"
+	}
+	s := rate.rat.FloatString(places)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+func (rate Rate) MarshalText() ([]byte, error) {
+	return []byte(rate.String()), nil
+}
+
+func (rate *Rate) UnmarshalText(text []byte) error {
+	parsed, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	*rate = parsed
+	return nil
+}
+
+// decimalPlaces returns the number of decimals of 1/denom,
+// or false if it has no finite decimal expansion because denom has a prime factor other than 2 and 5.
+func decimalPlaces(denom *big.Int) (int, bool) {
+	rest := new(big.Int).Set(denom)
+	places := 0
+	for _, factor := range []int64{2, 5} {
+		n := 0
+		for {
+			quo, mod := new(big.Int).QuoRem(rest, big.NewInt(factor), new(big.Int))
+			if mod.Sign() != 0 {
+				break
+			}
+			rest = quo
+			n++
+		}
+		places = max(places, n)
+	}
+	return places, rest.IsInt64() && rest.Int64() == 1
+}
+
+// Convert returns the minor units of target bought by amount minor units of source at rate,
+// rounded to the nearest unit with halves rounded away from zero. It computes with integers only,
+// and fails with currency.ErrOverflow when the result does not fit in an int64. The rate must be valid.
+func Convert(amount int64, rate Rate, source, target currency.Currency) (int64, error) {
+	num := new(big.Int).Mul(big.NewInt(amount), rate.rat.Num())
+	den := new(big.Int).Set(rate.rat.Denom())
+	if shift := target.Exponent - source.Exponent; shift > 0 {
+		num.Mul(num, pow10(shift))
+	} else if shift < 0 {
+		den.Mul(den, pow10(-shift))
+	}
+
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	// round up in magnitude when the remainder is at least half of the denominator
+	if rem.Lsh(rem.Abs(rem), 1).Cmp(den) >= 0 {
+		quo.Add(quo, big.NewInt(int64(num.Sign())))
+	}
+	if !quo.IsInt64() {
+		return 0, fmt.Errorf("%w: %d %s at %s in %s", currency.ErrOverflow, amount, source.Code, rate, target.Code)
+	}
+	return quo.Int64(), nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
@@ -0,0 +1,103 @@
+package fx
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"simplebank/currency"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseRate(t *testing.T, s string) Rate {
+	rate, err := ParseRate(s)
+	require.NoError(t, err)
+	return rate
+}
+
+func TestParseRate(t *testing.T) {
+	rate := parseRate(t, "0.92")
+	require.True(t, rate.IsValid())
+	require.Equal(t, "0.92", rate.String())
+	require.Equal(t, "25/23", rate.Inverse().String())
+	require.Equal(t, "1.086956521739", rate.Inverse().Decimal(12))
+	require.Equal(t, "0.92", rate.Decimal(12))
+	require.Equal(t, "150", parseRate(t, "150").String())
+	require.Equal(t, "0.0125", parseRate(t, "1/80").String())
+
+	for _, s := range []string{"", "0", "-1", "abc", "1/0"} {
+		_, err := ParseRate(s)
+		require.Error(t, err, s)
+	}
+	require.False(t, Rate{}.IsValid())
+}
+
+func TestRateJSON(t *testing.T) {
+	rate := parseRate(t, "25/23")
+	data, err := json.Marshal(rate)
+	require.NoError(t, err)
+	require.Equal(t, `"25/23"`, string(data))
+
+	var got Rate
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, rate.String(), got.String())
+
+	require.Error(t, json.Unmarshal([]byte(`"-1"`), &got))
+}
+
+func TestConvert(t *testing.T) {
+	usd, err := currency.Lookup("USD")
+	require.NoError(t, err)
+	jpy, err := currency.Lookup("JPY")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		amount int64
+		rate   string
+		source currency.Currency
+		target currency.Currency
+		want   int64
+	}{
+		{name: "SameExponent", amount: 1000, rate: "0.92", source: usd, target: usd, want: 920},
+		{name: "ToSmallerUnit", amount: 1234, rate: "150", source: usd, target: jpy, want: 1851},
+		{name: "ToLargerUnit", amount: 1851, rate: "1/150", source: jpy, target: usd, want: 1234},
+		// the float64 nearest to 1.005 is slightly less, so float math would round this half down to 100
+		{name: "HalfRoundsUp", amount: 1, rate: "1.005", source: jpy, target: usd, want: 101},
+		{name: "BelowHalfRoundsDown", amount: 1, rate: "1.0049", source: jpy, target: usd, want: 100},
+		{name: "Max", amount: math.MaxInt64, rate: "1", source: usd, target: usd, want: math.MaxInt64},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Convert(tc.amount, parseRate(t, tc.rate), tc.source, tc.target)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestConvertOverflow(t *testing.T) {
+	usd, err := currency.Lookup("USD")
+	require.NoError(t, err)
+	jpy, err := currency.Lookup("JPY")
+	require.NoError(t, err)
+
+	_, err = Convert(math.MaxInt64, parseRate(t, "150"), usd, usd)
+	require.ErrorIs(t, err, currency.ErrOverflow)
+
+	_, err = Convert(math.MaxInt64/10, parseRate(t, "1"), jpy, usd)
+	require.ErrorIs(t, err, currency.ErrOverflow)
+
+	quote := Quote{
+		SourceCurrency: "USD",
+		TargetCurrency: "JPY",
+		Rate:           parseRate(t, "150"),
+		SourceAmount:   math.MaxInt64,
+		TargetAmount:   1,
+	}
+	require.ErrorIs(t, quote.Validate(quote.ExpiresAt), currency.ErrOverflow)
+}
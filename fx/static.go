@@ -0,0 +1,125 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"simplebank/currency"
+)
+
+// DefaultQuoteTTL is how long a quote from a StaticProvider stays valid when no TTL is given.
+const DefaultQuoteTTL = time.Minute
+
+// StaticProvider quotes from a fixed table of rates, for tests and offline use.
+// A pair missing from the table is quoted with the inverse of the opposite pair, if present.
+type StaticProvider struct {
+	rates map[string]Rate
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+var _ RateProvider = (*StaticProvider)(nil)
+
+// NewStaticProvider creates a provider from rates keyed by pair, like "USD/EUR": "0.92",
+// meaning that one USD buys 0.92 EUR. Rates are parsed with ParseRate.
+// Quotes stay valid for ttl, or DefaultQuoteTTL when ttl is zero.
+func NewStaticProvider(rates map[string]string, ttl time.Duration) (*StaticProvider, error) {
+	if ttl == 0 {
+		ttl = DefaultQuoteTTL
+	}
+	if ttl < 0 {
+		return nil, fmt.Errorf("invalid quote ttl %s", ttl)
+	}
+
+	provider := &StaticProvider{
+		rates: make(map[string]Rate, len(rates)),
+		ttl:   ttl,
+		now:   time.Now,
+	}
+	for pair, rate := range rates {
+		source, target, ok := strings.Cut(pair, "/")
+		if !ok || source == "" || target == "" || source == target {
+			return nil, fmt.Errorf("invalid currency pair %q", pair)
+		}
+		parsed, err := ParseRate(rate)
+		if err != nil {
+			return nil, fmt.Errorf("%w for %s", err, pair)
+		}
+		provider.rates[pair] = parsed
+	}
+	return provider, nil
+}
+
+// LoadFileProvider creates a StaticProvider from a JSON file holding an object of rates keyed by pair,
+// like {"USD/EUR": 0.92, "USD/CAD": 1.36}. The rates are read as written, without going through a float.
+func LoadFileProvider(path string, ttl time.Duration) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var numbers map[string]json.Number
+	if err := json.Unmarshal(data, &numbers); err != nil {
+		return nil, fmt.Errorf("cannot parse rates file %s: %w", path, err)
+	}
+	rates := make(map[string]string, len(numbers))
+	for pair, number := range numbers {
+		rates[pair] = number.String()
+	}
+	return NewStaticProvider(rates, ttl)
+}
+
+// Rate returns how many target units one source unit buys.
+func (provider *StaticProvider) Rate(source, target string) (Rate, error) {
+	if source == target {
+		return ParseRate("1")
+	}
+	if rate, ok := provider.rates[source+"/"+target]; ok {
+		return rate, nil
+	}
+	if rate, ok := provider.rates[target+"/"+source]; ok {
+		return rate.Inverse(), nil
+	}
+	return Rate{}, fmt.Errorf("%w: %s/%s", ErrUnsupportedPair, source, target)
+}
+
+func (provider *StaticProvider) Quote(ctx context.Context, source, target string, sourceAmount int64) (Quote, error) {
+	if sourceAmount <= 0 {
+		return Quote{}, fmt.Errorf("%w: source amount %d", ErrInvalidQuote, sourceAmount)
+	}
+
+	rate, err := provider.Rate(source, target)
+	if err != nil {
+		return Quote{}, err
+	}
+	sourceCurrency, err := currency.Lookup(source)
+	if err != nil {
+		return Quote{}, err
+	}
+	targetCurrency, err := currency.Lookup(target)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	targetAmount, err := Convert(sourceAmount, rate, sourceCurrency, targetCurrency)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	quote := Quote{
+		SourceCurrency: source,
+		TargetCurrency: target,
+		Rate:           rate,
+		SourceAmount:   sourceAmount,
+		TargetAmount:   targetAmount,
+		ExpiresAt:      provider.now().Add(provider.ttl),
+	}
+	if quote.TargetAmount <= 0 {
+		return Quote{}, fmt.Errorf("%w: %d %s buys nothing in %s", ErrInvalidQuote, sourceAmount, source, target)
+	}
+	return quote, nil
+}
@@ -0,0 +1,110 @@
+package fx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProviderQuote(t *testing.T) {
+	provider, err := NewStaticProvider(map[string]string{"USD/EUR": "0.92", "CAD/CHF": "0.1", "USD/JPY": "150", "USD/BHD": "0.376"}, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time { return now }
+
+	quote, err := provider.Quote(context.Background(), "USD", "EUR", 1000)
+	require.NoError(t, err)
+	require.Equal(t, "USD", quote.SourceCurrency)
+	require.Equal(t, "EUR", quote.TargetCurrency)
+	require.Equal(t, "0.92", quote.Rate.String())
+	require.Equal(t, int64(1000), quote.SourceAmount)
+	require.Equal(t, int64(920), quote.TargetAmount)
+	require.Equal(t, now.Add(time.Minute), quote.ExpiresAt)
+	require.NoError(t, quote.Validate(now))
+	require.ErrorIs(t, quote.Validate(now.Add(time.Minute)), ErrQuoteExpired)
+
+	// the target amount must be the source amount at the rate
+	forged := quote
+	forged.TargetAmount = 1_000_000
+	require.ErrorIs(t, forged.Validate(now), ErrInvalidQuote)
+	forged = quote
+	forged.SourceAmount = 1
+	require.ErrorIs(t, forged.Validate(now), ErrInvalidQuote)
+	forged = quote
+	forged.TargetCurrency = "XYZ"
+	require.ErrorIs(t, forged.Validate(now), ErrInvalidQuote)
+	forged = quote
+	forged.Rate = Rate{}
+	require.ErrorIs(t, forged.Validate(now), ErrInvalidQuote)
+
+	// the opposite pair is quoted at the exact inverse rate
+	quote, err = provider.Quote(context.Background(), "EUR", "USD", 920)
+	require.NoError(t, err)
+	require.Equal(t, "25/23", quote.Rate.String())
+	require.Equal(t, int64(1000), quote.TargetAmount)
+	require.NoError(t, quote.Validate(now))
+
+	// amounts are converted between minor units of different sizes
+	quote, err = provider.Quote(context.Background(), "USD", "JPY", 1234)
+	require.NoError(t, err)
+	require.Equal(t, int64(1851), quote.TargetAmount)
+	quote, err = provider.Quote(context.Background(), "JPY", "USD", 1851)
+	require.NoError(t, err)
+	require.Equal(t, int64(1234), quote.TargetAmount)
+	quote, err = provider.Quote(context.Background(), "USD", "BHD", 10000)
+	require.NoError(t, err)
+	require.Equal(t, int64(37600), quote.TargetAmount)
+
+	_, err = provider.Quote(context.Background(), "USD", "GBP", 1000)
+	require.ErrorIs(t, err, ErrUnsupportedPair)
+
+	_, err = provider.Quote(context.Background(), "USD", "EUR", 0)
+	require.ErrorIs(t, err, ErrInvalidQuote)
+
+	// too small to buy a single unit
+	_, err = provider.Quote(context.Background(), "CAD", "CHF", 4)
+	require.ErrorIs(t, err, ErrInvalidQuote)
+}
+
+func TestNewStaticProviderErrors(t *testing.T) {
+	for _, rates := range []map[string]string{
+		{"USDEUR": "0.92"},
+		{"USD/": "0.92"},
+		{"USD/USD": "1"},
+		{"USD/EUR": "0"},
+		{"USD/EUR": "-1"},
+		{"USD/EUR": "abc"},
+	} {
+		_, err := NewStaticProvider(rates, 0)
+		require.Error(t, err, rates)
+	}
+
+	_, err := NewStaticProvider(nil, -time.Second)
+	require.Error(t, err)
+}
+
+func TestLoadFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"USD/CAD": 1.36}`), 0o600))
+
+	provider, err := LoadFileProvider(path, 0)
+	require.NoError(t, err)
+	require.Equal(t, DefaultQuoteTTL, provider.ttl)
+
+	rate, err := provider.Rate("USD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, "1.36", rate.String())
+
+	require.NoError(t, os.WriteFile(path, []byte(`not json`), 0o600))
+	_, err = LoadFileProvider(path, 0)
+	require.Error(t, err)
+
+	_, err = LoadFileProvider(filepath.Join(dir, "missing.json"), 0)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,105 @@
+package gapi
+
+import (
+	"database/sql"
+
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var accountStatuses = map[db.AccountStatus]pb.AccountStatus{
+	db.AccountStatusActive: pb.AccountStatus_ACCOUNT_STATUS_ACTIVE,
+	db.AccountStatusFrozen: pb.AccountStatus_ACCOUNT_STATUS_FROZEN,
+	db.AccountStatusClosed: pb.AccountStatus_ACCOUNT_STATUS_CLOSED,
+}
+
+var entryTypes = map[db.EntryType]pb.EntryType{
+	db.EntryTypeTransfer:   pb.EntryType_ENTRY_TYPE_TRANSFER,
+	db.EntryTypeDeposit:    pb.EntryType_ENTRY_TYPE_DEPOSIT,
+	db.EntryTypeWithdrawal: pb.EntryType_ENTRY_TYPE_WITHDRAWAL,
+	db.EntryTypeFee:        pb.EntryType_ENTRY_TYPE_FEE,
+	db.EntryTypeAdjustment: pb.EntryType_ENTRY_TYPE_ADJUSTMENT,
+}
+
+var transferStatuses = map[db.TransferStatus]pb.TransferStatus{
+	db.TransferStatusPending: pb.TransferStatus_TRANSFER_STATUS_PENDING,
+	db.TransferStatusPosted:  pb.TransferStatus_TRANSFER_STATUS_POSTED,
+	db.TransferStatusFailed:  pb.TransferStatus_TRANSFER_STATUS_FAILED,
+}
+
+// dbAccountStatus is the inverse of accountStatuses, for filters. Unspecified means no filter.
+func dbAccountStatus(accountStatus pb.AccountStatus) db.NullAccountStatus {
+	for dbStatus, pbStatus := range accountStatuses {
+		if pbStatus == accountStatus {
+			return db.NullAccountStatus{AccountStatus: dbStatus, Valid: true}
+		}
+	}
+	return db.NullAccountStatus{}
+}
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+		HeldBalance:      account.HeldBalance,
+		AvailableBalance: account.AvailableBalance,
+		Status:           accountStatuses[account.Status],
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:          entry.ID,
+		AccountId:   entry.AccountID,
+		Amount:      entry.Amount,
+		CreatedAt:   timestamppb.New(entry.CreatedAt),
+		Type:        entryTypes[entry.Type],
+		TransferId:  optionalInt64(entry.TransferID),
+		ExternalRef: optionalString(entry.ExternalRef),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:             transfer.ID,
+		FromAccountId:  transfer.FromAccountID,
+		ToAccountId:    transfer.ToAccountID,
+		Amount:         transfer.Amount,
+		CreatedAt:      timestamppb.New(transfer.CreatedAt),
+		Status:         transferStatuses[transfer.Status],
+		ReversalOf:     optionalInt64(transfer.ReversalOf),
+		Reason:         optionalString(transfer.Reason),
+		ReversedAmount: transfer.ReversedAmount,
+		ToAmount:       optionalInt64(transfer.ToAmount),
+		ExchangeRate:   optionalString(transfer.ExchangeRate),
+	}
+}
+
+func optionalInt64(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+func optionalString(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"database/sql"
+	"errors"
+
+	db "simplebank/db/sqlc"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errAccountNotOwned = errors.New("account doesn't belong to the authenticated user")
+
+// storeError maps an error returned by the store to a gRPC status,
+// along the same lines as the HTTP API maps it to a status code.
+func storeError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, db.ErrInvalidAmount), errors.Is(err, db.ErrAmountOverflow):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, db.ErrCurrencyMismatch), errors.Is(err, db.ErrInsufficientFunds),
+		errors.Is(err, db.ErrAccountFrozen), errors.Is(err, db.ErrAccountClosed),
+		errors.Is(err, db.ErrAccountNotEmpty), errors.Is(err, db.ErrAccountPendingActivity):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, db.ErrIdempotencyKeyConflict), errors.Is(err, db.ErrUniqueViolation):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, db.ErrForeignKeyViolation):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	switch db.ErrorCode(err) {
+	case db.CheckViolation, db.NotNullViolation:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// invalidArgumentError reports every field of a request that failed validation.
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
+
+	statusDetails, err := statusInvalid.WithDetails(badRequest)
+	if err != nil {
+		return statusInvalid.Err()
+	}
+	return statusDetails.Err()
+}
+
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	}
+}
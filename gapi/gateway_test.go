@@ -0,0 +1,122 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestGateway goes through the HTTP/JSON gateway to the memory store, to check that the HTTP mapping,
+// the authorization header and the request ID all reach the gRPC implementation.
+func TestGateway(t *testing.T) {
+	store := db.NewMemoryStore()
+	server := newTestServer(t, store)
+
+	gateway, err := NewGateway(context.Background(), newTestConn(t, server))
+	require.NoError(t, err)
+
+	serve := func(method, url, accessToken string, body any) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		request, err := http.NewRequest(method, url, bytes.NewReader(data))
+		require.NoError(t, err)
+		request.Header.Set("X-Request-ID", method+" "+url)
+		if accessToken != "" {
+			request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+		}
+
+		recorder := httptest.NewRecorder()
+		gateway.ServeHTTP(recorder, request)
+		require.Equal(t, method+" "+url, recorder.Header().Get("X-Request-ID"))
+		return recorder
+	}
+
+	user, password := randomUser(t)
+	recorder := serve(http.MethodPost, "/v1/users", "", map[string]string{
+		"username":  user.Username,
+		"password":  password,
+		"full_name": user.FullName,
+		"email":     user.Email,
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = serve(http.MethodPost, "/v1/users/login", "", map[string]string{
+		"username": user.Username,
+		"password": password,
+	})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var login struct {
+		AccessToken string `json:"access_token"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &login))
+	require.NotEmpty(t, login.AccessToken)
+
+	recorder = serve(http.MethodPost, "/v1/accounts", "", map[string]string{"currency": utils.USD})
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = serve(http.MethodPost, "/v1/accounts", login.AccessToken, map[string]string{"currency": "XYZ"})
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = serve(http.MethodPost, "/v1/accounts", login.AccessToken, map[string]string{"currency": utils.USD})
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var created struct {
+		Account struct {
+			ID       string `json:"id"`
+			Owner    string `json:"owner"`
+			Currency string `json:"currency"`
+			Status   string `json:"status"`
+		} `json:"account"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+	require.Equal(t, user.Username, created.Account.Owner)
+	require.Equal(t, utils.USD, created.Account.Currency)
+	require.Equal(t, "ACCOUNT_STATUS_ACTIVE", created.Account.Status)
+
+	// the audit log records who created the account, and in which request
+	accountID, err := strconv.ParseInt(created.Account.ID, 10, 64)
+	require.NoError(t, err)
+	logs, err := store.ListAuditLogByEntity(context.Background(), db.ListAuditLogByEntityParams{
+		Entity:   db.AuditEntityAccount,
+		EntityID: created.Account.ID,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, user.Username, logs[0].Actor.String)
+	require.Equal(t, "POST /v1/accounts", logs[0].RequestID.String)
+
+	url := fmt.Sprintf("/v1/accounts/%d", accountID)
+	recorder = serve(http.MethodGet, url, login.AccessToken, nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	other := createFundedAccount(t, store, utils.USD, 0)
+	recorder = serve(http.MethodGet, fmt.Sprintf("/v1/accounts/%d", other.ID), login.AccessToken, nil)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = serve(http.MethodGet, "/v1/accounts/999999", login.AccessToken, nil)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = serve(http.MethodDelete, url, login.AccessToken, nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	recorder = serve(http.MethodGet, "/v1/accounts?page_size=5&status=ACCOUNT_STATUS_CLOSED", login.AccessToken, nil)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	var listed struct {
+		Accounts []struct {
+			ID string `json:"id"`
+		} `json:"accounts"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+	require.Len(t, listed.Accounts, 1)
+	require.Equal(t, created.Account.ID, listed.Accounts[0].ID)
+}
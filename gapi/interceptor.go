@@ -0,0 +1,116 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/textproto"
+	"strings"
+
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+	"simplebank/token"
+
+	"github.com/google/uuid"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+
+	requestIDHeaderKey = "x-request-id"
+)
+
+// publicMethods can be called without an access token.
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName: true,
+	pb.SimpleBank_LoginUser_FullMethodName:  true,
+}
+
+type authPayloadKey struct{}
+
+// requestIDInterceptor gives every call an ID, taken from the x-request-id metadata or generated,
+// sends it back in the response header and hands it to the store, which records it in the audit log.
+func requestIDInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeaderKey); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(requestIDHeaderKey, requestID)); err != nil {
+		return nil, err
+	}
+	return handler(db.WithRequestID(ctx, requestID), req)
+}
+
+// authInterceptor verifies the bearer access token of every call to a method that is not public,
+// and hands its username to the handler and to the store.
+func (server *Server) authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	payload, err := server.verifyAccessToken(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctx = context.WithValue(ctx, authPayloadKey{}, payload)
+	return handler(db.WithActor(ctx, payload.Username), req)
+}
+
+func (server *Server) verifyAccessToken(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeaderKey)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("authorization header is not provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, fmt.Errorf("unsupported authorization type %s", authorizationType)
+	}
+
+	return server.tokenMaker.VerifyToken(fields[1])
+}
+
+// authPayload returns the token payload stored by authInterceptor
+func authPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authPayloadKey{}).(*token.Payload)
+}
+
+// incomingHeaderMatcher passes the X-Request-ID header of a gateway request on as metadata,
+// and leaves every other header to the gateway default.
+func incomingHeaderMatcher(key string) (string, bool) {
+	if textproto.CanonicalMIMEHeaderKey(key) == textproto.CanonicalMIMEHeaderKey(requestIDHeaderKey) {
+		return requestIDHeaderKey, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeaderMatcher returns the request ID of a call in the X-Request-ID header of the gateway response,
+// and every other metadata in a Grpc-Metadata- header like the gateway default.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == requestIDHeaderKey {
+		return textproto.CanonicalMIMEHeaderKey(requestIDHeaderKey), true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
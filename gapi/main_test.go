@@ -0,0 +1,114 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/pb"
+	"simplebank/token"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := utils.Config{
+		TokenSymmetricKey:   utils.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	return server
+}
+
+// newTestConn serves the server in process over a bufconn listener and returns a client connection to it.
+// Both are closed when the test ends.
+func newTestConn(t *testing.T, server *Server) *grpc.ClientConn {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func newTestClient(t *testing.T, store db.Store) (*Server, pb.SimpleBankClient) {
+	server := newTestServer(t, store)
+	return server, pb.NewSimpleBankClient(newTestConn(t, server))
+}
+
+func withAuthorization(t *testing.T, ctx context.Context, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	accessToken, payload, err := tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	authorizationHeader := fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)
+	return metadata.AppendToOutgoingContext(ctx, authorizationHeaderKey, authorizationHeader)
+}
+
+func requireCode(t *testing.T, err error, code codes.Code) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code(), st.Message())
+}
+
+// requireFieldViolations checks that err is an InvalidArgument status reporting exactly the given fields.
+func requireFieldViolations(t *testing.T, err error, fields ...string) {
+	requireCode(t, err, codes.InvalidArgument)
+
+	var got []string
+	for _, detail := range status.Convert(err).Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		require.True(t, ok)
+		for _, violation := range badRequest.GetFieldViolations() {
+			got = append(got, violation.GetField())
+		}
+	}
+	require.ElementsMatch(t, fields, got)
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = utils.RandomString(6)
+	hashedPassword, err := utils.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username:       utils.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	}
+	return
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		ID:       int64(utils.RandomInt(1, 1000)),
+		Owner:    owner,
+		Balance:  utils.RandomMoney(),
+		Currency: utils.RandomCurrency(),
+		Status:   db.AccountStatusActive,
+	}
+}
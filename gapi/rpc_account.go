@@ -0,0 +1,111 @@
+package gapi
+
+import (
+	"context"
+
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("currency", err)})
+	}
+
+	arg := db.CreateAccountParams{
+		Owner:    authPayload(ctx).Username,
+		Currency: req.GetCurrency(),
+		Balance:  0,
+	}
+
+	account, err := server.store.CreateAccountTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	account, err := server.ownedAccount(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	if violations := validatePage(req.GetPageToken(), req.GetPageSize()); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	page, err := db.PageAccountsByOwner(ctx, server.store, authPayload(ctx).Username, dbAccountStatus(req.GetStatus()), db.PageRequest{
+		PageToken: req.GetPageToken(),
+		PageSize:  req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListAccountsResponse{
+		Accounts:      make([]*pb.Account, len(page.Items)),
+		NextPageToken: page.NextPageToken,
+	}
+	for i, account := range page.Items {
+		rsp.Accounts[i] = convertAccount(account)
+	}
+	return rsp, nil
+}
+
+// CloseAccount closes an account instead of deleting it, so that its history stays.
+// The balance must be zero and nothing may be pending on the account.
+func (server *Server) CloseAccount(ctx context.Context, req *pb.CloseAccountRequest) (*pb.CloseAccountResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	if _, err := server.ownedAccount(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	account, err := server.store.CloseAccountTx(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CloseAccountResponse{Account: convertAccount(account)}, nil
+}
+
+// ownedAccount loads an account and checks that it belongs to the authenticated user.
+// The error it returns is already a gRPC status.
+func (server *Server) ownedAccount(ctx context.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, storeError(err)
+	}
+
+	if account.Owner != authPayload(ctx).Username {
+		return account, status.Error(codes.PermissionDenied, errAccountNotOwned.Error())
+	}
+
+	return account, nil
+}
+
+func validatePage(pageToken string, pageSize int32) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validatePageToken(pageToken); err != nil {
+		violations = append(violations, fieldViolation("page_token", err))
+	}
+	if err := validatePageSize(pageSize); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+	return violations
+}
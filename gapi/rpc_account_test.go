@@ -0,0 +1,276 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+	"simplebank/token"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestGetAccountRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		setupAuth     func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.GetAccountResponse, err error)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withAuthorization(t, ctx, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				got := rsp.GetAccount()
+				require.Equal(t, account.ID, got.GetId())
+				require.Equal(t, account.Owner, got.GetOwner())
+				require.Equal(t, account.Balance, got.GetBalance())
+				require.Equal(t, account.Currency, got.GetCurrency())
+				require.Equal(t, pb.AccountStatus_ACCOUNT_STATUS_ACTIVE, got.GetStatus())
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withAuthorization(t, ctx, tokenMaker, "unauthorized", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				requireCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return ctx
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				requireCode(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name:      "ExpiredToken",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withAuthorization(t, ctx, tokenMaker, user.Username, -time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				requireCode(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withAuthorization(t, ctx, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				requireCode(t, err, codes.NotFound)
+			},
+		},
+		{
+			name:      "InternalError",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withAuthorization(t, ctx, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				requireCode(t, err, codes.Internal)
+			},
+		},
+		{
+			name:      "InvalidID",
+			accountID: 0,
+			setupAuth: func(t *testing.T, ctx context.Context, tokenMaker token.Maker) context.Context {
+				return withAuthorization(t, ctx, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetAccountResponse, err error) {
+				requireFieldViolations(t, err, "id")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, client := newTestClient(t, store)
+			ctx := tc.setupAuth(t, context.Background(), server.tokenMaker)
+			rsp, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: tc.accountID})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestListAccountsRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	accounts := []db.Account{randomAccount(user.Username), randomAccount(user.Username)}
+	after := db.PageCursor{CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), ID: 42}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListAccountsRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ListAccountsResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ListAccountsRequest{PageToken: after.Token(), PageSize: 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsByOwnerPageParams{
+					Owner:          user.Username,
+					AfterCreatedAt: after.CreatedAt,
+					AfterID:        after.ID,
+					PageSize:       6,
+				}
+				store.EXPECT().ListAccountsByOwnerPage(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetAccounts(), len(accounts))
+				for i, account := range accounts {
+					require.Equal(t, account.ID, rsp.GetAccounts()[i].GetId())
+				}
+				require.Empty(t, rsp.GetNextPageToken())
+			},
+		},
+		{
+			name: "FilterByStatus",
+			req:  &pb.ListAccountsRequest{PageSize: 5, Status: pb.AccountStatus_ACCOUNT_STATUS_FROZEN},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsByOwnerPageParams{
+					Owner:    user.Username,
+					Status:   db.NullAccountStatus{AccountStatus: db.AccountStatusFrozen, Valid: true},
+					PageSize: 6,
+				}
+				store.EXPECT().ListAccountsByOwnerPage(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Account{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				require.NoError(t, err)
+				require.Empty(t, rsp.GetAccounts())
+			},
+		},
+		{
+			name: "InvalidPage",
+			req:  &pb.ListAccountsRequest{PageToken: "%%%", PageSize: 100},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsByOwnerPage(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListAccountsResponse, err error) {
+				requireFieldViolations(t, err, "page_token", "page_size")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, client := newTestClient(t, store)
+			ctx := withAuthorization(t, context.Background(), server.tokenMaker, user.Username, time.Minute)
+			rsp, err := client.ListAccounts(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestCloseAccountRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.CloseAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				closed := account
+				closed.Balance = 0
+				closed.Status = db.AccountStatusClosed
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closed, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CloseAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, pb.AccountStatus_ACCOUNT_STATUS_CLOSED, rsp.GetAccount().GetStatus())
+			},
+		},
+		{
+			name: "NotEmpty",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrAccountNotEmpty)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CloseAccountResponse, err error) {
+				requireCode(t, err, codes.FailedPrecondition)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, client := newTestClient(t, store)
+			ctx := withAuthorization(t, context.Background(), server.tokenMaker, user.Username, time.Minute)
+			rsp, err := client.CloseAccount(ctx, &pb.CloseAccountRequest{Id: account.ID})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"context"
+
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) GetEntry(ctx context.Context, req *pb.GetEntryRequest) (*pb.GetEntryResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	entry, err := server.store.GetEntry(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	if _, err := server.ownedAccount(ctx, entry.AccountID); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetEntryResponse{Entry: convertEntry(entry)}, nil
+}
+
+func (server *Server) ListEntries(ctx context.Context, req *pb.ListEntriesRequest) (*pb.ListEntriesResponse, error) {
+	violations := validatePage(req.GetPageToken(), req.GetPageSize())
+	if err := validateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	if _, err := server.ownedAccount(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	page, err := db.PageEntriesByAccount(ctx, server.store, req.GetAccountId(), db.PageRequest{
+		PageToken: req.GetPageToken(),
+		PageSize:  req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListEntriesResponse{
+		Entries:       make([]*pb.Entry, len(page.Items)),
+		NextPageToken: page.NextPageToken,
+	}
+	for i, entry := range page.Items {
+		rsp.Entries[i] = convertEntry(entry)
+	}
+	return rsp, nil
+}
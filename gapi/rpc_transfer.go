@@ -0,0 +1,91 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	db "simplebank/db/sqlc"
+	"simplebank/pb"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	if violations := validateCreateTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	// The store re-checks both accounts inside the transfer transaction; this gives the client a clearer error early.
+	account, err := server.ownedAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		return nil, err
+	}
+	if account.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, req.GetCurrency())
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID:  req.GetFromAccountId(),
+		ToAccountID:    req.GetToAccountId(),
+		Amount:         db.Amount(req.GetAmount()),
+		IdempotencyKey: req.GetIdempotencyKey(),
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}
+	return rsp, nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := validateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	} else if req.GetToAccountId() == req.GetFromAccountId() {
+		violations = append(violations, fieldViolation("to_account_id", fmt.Errorf("must differ from from_account_id")))
+	}
+	if err := validateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	return violations
+}
+
+func (server *Server) GetTransfer(ctx context.Context, req *pb.GetTransferRequest) (*pb.GetTransferResponse, error) {
+	if err := validateID(req.GetId()); err != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.GetId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	// either side of the transfer may look at it
+	fromAccount, err := server.store.GetAccount(ctx, transfer.FromAccountID)
+	if err != nil {
+		return nil, storeError(err)
+	}
+	if fromAccount.Owner != authPayload(ctx).Username {
+		if _, err := server.ownedAccount(ctx, transfer.ToAccountID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.GetTransferResponse{Transfer: convertTransfer(transfer)}, nil
+}
@@ -0,0 +1,141 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/pb"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// TestTransferRPC runs against the memory store, from the transfer through to the entries it posts.
+func TestTransferRPC(t *testing.T) {
+	store := db.NewMemoryStore()
+	server, client := newTestClient(t, store)
+
+	currency := utils.RandomCurrency()
+	account1 := createFundedAccount(t, store, currency, 100)
+	account2 := createFundedAccount(t, store, currency, 0)
+	ctx1 := withAuthorization(t, context.Background(), server.tokenMaker, account1.Owner, time.Minute)
+	ctx2 := withAuthorization(t, context.Background(), server.tokenMaker, account2.Owner, time.Minute)
+
+	req := &pb.CreateTransferRequest{
+		FromAccountId:  account1.ID,
+		ToAccountId:    account2.ID,
+		Amount:         30,
+		Currency:       currency,
+		IdempotencyKey: utils.RandomString(16),
+	}
+	rsp, err := client.CreateTransfer(ctx1, req)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), rsp.GetTransfer().GetAmount())
+	require.Equal(t, pb.TransferStatus_TRANSFER_STATUS_POSTED, rsp.GetTransfer().GetStatus())
+	require.Equal(t, int64(70), rsp.GetFromAccount().GetBalance())
+	require.Equal(t, int64(30), rsp.GetToAccount().GetBalance())
+	require.Equal(t, int64(-30), rsp.GetFromEntry().GetAmount())
+	require.Equal(t, rsp.GetTransfer().GetId(), rsp.GetToEntry().GetTransferId())
+
+	// a retry with the same idempotency key moves the money only once
+	retry, err := client.CreateTransfer(ctx1, req)
+	require.NoError(t, err)
+	require.Equal(t, rsp.GetTransfer().GetId(), retry.GetTransfer().GetId())
+
+	// either side may look at the transfer, but only the owner at the entries
+	for _, ctx := range []context.Context{ctx1, ctx2} {
+		got, err := client.GetTransfer(ctx, &pb.GetTransferRequest{Id: rsp.GetTransfer().GetId()})
+		require.NoError(t, err)
+		require.Equal(t, account2.ID, got.GetTransfer().GetToAccountId())
+	}
+	_, err = client.GetEntry(ctx2, &pb.GetEntryRequest{Id: rsp.GetFromEntry().GetId()})
+	requireCode(t, err, codes.PermissionDenied)
+
+	entries, err := client.ListEntries(ctx2, &pb.ListEntriesRequest{AccountId: account2.ID, PageSize: 5})
+	require.NoError(t, err)
+	require.Len(t, entries.GetEntries(), 1)
+	require.Equal(t, pb.EntryType_ENTRY_TYPE_TRANSFER, entries.GetEntries()[0].GetType())
+
+	testCases := []struct {
+		name  string
+		ctx   context.Context
+		req   *pb.CreateTransferRequest
+		check func(t *testing.T, err error)
+	}{
+		{
+			name: "InsufficientFunds",
+			ctx:  ctx1,
+			req:  &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 1000, Currency: currency},
+			check: func(t *testing.T, err error) {
+				requireCode(t, err, codes.FailedPrecondition)
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			ctx:  ctx2,
+			req:  &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10, Currency: currency},
+			check: func(t *testing.T, err error) {
+				requireCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			ctx:  ctx1,
+			req:  &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10, Currency: otherCurrency(currency)},
+			check: func(t *testing.T, err error) {
+				requireCode(t, err, codes.InvalidArgument)
+			},
+		},
+		{
+			name: "InvalidArguments",
+			ctx:  ctx1,
+			req:  &pb.CreateTransferRequest{FromAccountId: account1.ID, ToAccountId: account1.ID, Amount: -1, Currency: "XYZ"},
+			check: func(t *testing.T, err error) {
+				requireFieldViolations(t, err, "to_account_id", "amount", "currency")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.CreateTransfer(tc.ctx, tc.req)
+			tc.check(t, err)
+		})
+	}
+
+	account, err := store.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(70), account.Balance)
+}
+
+// createFundedAccount creates a user and an account of theirs holding balance.
+func createFundedAccount(t *testing.T, store db.Store, currency string, balance int64) db.Account {
+	user, _ := randomUser(t)
+	_, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: user.HashedPassword,
+		FullName:       user.FullName,
+		Email:          user.Email,
+	})
+	require.NoError(t, err)
+
+	account, err := store.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func otherCurrency(currency string) string {
+	if currency == utils.USD {
+		return utils.EUR
+	}
+	return utils.USD
+}
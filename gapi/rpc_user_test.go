@@ -0,0 +1,176 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "simplebank/db/mock"
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/pb"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateUserRPC(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateUserRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.CreateUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Password: password,
+				FullName: user.FullName,
+				Email:    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateUserParams) (db.User, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.NoError(t, utils.CheckPassword(password, arg.HashedPassword))
+						return user, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+				require.Equal(t, user.FullName, rsp.GetUser().GetFullName())
+				require.Equal(t, user.Email, rsp.GetUser().GetEmail())
+			},
+		},
+		{
+			name: "DuplicateUsername",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				Password: password,
+				FullName: user.FullName,
+				Email:    user.Email,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &db.ConstraintError{Kind: db.ErrUniqueViolation, Err: &pq.Error{Code: db.UniqueViolation}})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateUserResponse, err error) {
+				requireCode(t, err, codes.AlreadyExists)
+			},
+		},
+		{
+			name: "InvalidArguments",
+			req: &pb.CreateUserRequest{
+				Username: "invalid-user#1",
+				Password: "123",
+				FullName: user.FullName,
+				Email:    "invalid-email",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateUserResponse, err error) {
+				requireFieldViolations(t, err, "username", "password", "email")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			_, client := newTestClient(t, store)
+			rsp, err := client.CreateUser(context.Background(), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestLoginUserRPC(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		req           *pb.LoginUserRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+
+				payload, err := server.tokenMaker.VerifyToken(rsp.GetAccessToken())
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+			},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+				requireCode(t, err, codes.NotFound)
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: "incorrect"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+				requireCode(t, err, codes.Unauthenticated)
+			},
+		},
+		{
+			name: "InvalidUsername",
+			req:  &pb.LoginUserRequest{Username: "invalid-user#1", Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, rsp *pb.LoginUserResponse, err error) {
+				requireFieldViolations(t, err, "username")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, client := newTestClient(t, store)
+			rsp, err := client.LoginUser(context.Background(), tc.req)
+			tc.checkResponse(t, server, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/pb"
+	"simplebank/token"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Server serves gRPC requests for our banking service.
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config     utils.Config
+	store      db.Store
+	tokenMaker token.Maker
+}
+
+// NewServer creates a new gRPC server.
+func NewServer(config utils.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+	}
+	return server, nil
+}
+
+// GRPCServer returns a grpc.Server that serves the bank with the request ID and authentication interceptors.
+func (server *Server) GRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(requestIDInterceptor, server.authInterceptor))
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	return grpcServer
+}
+
+// NewGateway returns an HTTP/JSON handler that translates every request into a gRPC call on conn,
+// so that both protocols go through the same implementation and interceptors.
+// JSON fields keep the snake_case names of the proto files, like the rest of the HTTP API.
+func NewGateway(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+	)
+
+	if err := pb.RegisterSimpleBankHandler(ctx, mux, conn); err != nil {
+		return nil, fmt.Errorf("cannot register gateway: %w", err)
+	}
+	return mux, nil
+}
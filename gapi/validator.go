@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+)
+
+var isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+
+// The validators below apply the same rules as the binding tags of the HTTP API.
+
+func validateUsername(value string) error {
+	if value == "" {
+		return fmt.Errorf("is required")
+	}
+	if !isAlphanumeric(value) {
+		return fmt.Errorf("must contain only letters and digits")
+	}
+	return nil
+}
+
+func validatePassword(value string) error {
+	if len(value) < 6 {
+		return fmt.Errorf("must contain at least 6 characters")
+	}
+	return nil
+}
+
+func validateFullName(value string) error {
+	if value == "" {
+		return fmt.Errorf("is required")
+	}
+	return nil
+}
+
+func validateEmail(value string) error {
+	if _, err := mail.ParseAddress(value); err != nil {
+		return fmt.Errorf("is not a valid email address")
+	}
+	return nil
+}
+
+func validateCurrency(value string) error {
+	if !utils.IsSupportedCurrency(value) {
+		return fmt.Errorf("unsupported currency %q", value)
+	}
+	return nil
+}
+
+func validateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("must be positive")
+	}
+	return nil
+}
+
+func validateAmount(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be positive")
+	}
+	return nil
+}
+
+func validatePageToken(value string) error {
+	if _, err := db.ParsePageToken(value); err != nil {
+		return fmt.Errorf("must be a next_page_token returned by the previous page")
+	}
+	return nil
+}
+
+func validatePageSize(value int32) error {
+	if value < 5 || value > 10 {
+		return fmt.Errorf("must be between 5 and 10")
+	}
+	return nil
+}
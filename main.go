@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"simplebank/api"
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/gapi"
+	"simplebank/outbox"
+
+	_ "github.com/lib/pq"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func main() {
+	config, err := utils.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config:", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db:", err)
+	}
+
+	store := db.NewStore(conn)
+	if config.HoldExpiryInterval > 0 {
+		go expireHolds(store, config.HoldExpiryInterval)
+	}
+	if config.OutboxPollInterval > 0 {
+		relay := outbox.NewRelay(store, outbox.LogPublisher{}, outbox.Options{PollInterval: config.OutboxPollInterval})
+		go relay.Run(context.Background())
+	}
+
+	if config.GRPCServerAddress != "" {
+		go runGRPCServer(config, store)
+		if config.GatewayServerAddress != "" {
+			go runGatewayServer(config)
+		}
+	}
+
+	server, err := api.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create server:", err)
+	}
+
+	err = server.Start(config.ServerAddress)
+	if err != nil {
+		log.Fatal("cannot start server:", err)
+	}
+}
+
+// runGRPCServer serves the bank over gRPC on config.GRPCServerAddress.
+func runGRPCServer(config utils.Config, store db.Store) {
+	server, err := gapi.NewServer(config, store)
+	if err != nil {
+		log.Fatal("cannot create gRPC server:", err)
+	}
+
+	listener, err := net.Listen("tcp", config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal("cannot create gRPC listener:", err)
+	}
+
+	log.Printf("start gRPC server at %s", listener.Addr())
+	err = server.GRPCServer().Serve(listener)
+	if err != nil {
+		log.Fatal("cannot start gRPC server:", err)
+	}
+}
+
+// runGatewayServer serves the HTTP/JSON gateway on config.GatewayServerAddress,
+// forwarding every request to the gRPC server.
+func runGatewayServer(config utils.Config) {
+	conn, err := grpc.Dial(config.GRPCServerAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatal("cannot dial gRPC server:", err)
+	}
+
+	gateway, err := gapi.NewGateway(context.Background(), conn)
+	if err != nil {
+		log.Fatal("cannot create gateway:", err)
+	}
+
+	log.Printf("start HTTP gateway at %s", config.GatewayServerAddress)
+	err = http.ListenAndServe(config.GatewayServerAddress, gateway)
+	if err != nil {
+		log.Fatal("cannot start HTTP gateway:", err)
+	}
+}
+
+// expireHolds releases the holds that expired, every interval, for as long as the server runs.
+func expireHolds(store db.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		n, err := store.ExpireHolds(context.Background(), time.Now())
+		if err != nil {
+			log.Println("cannot expire holds:", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("expired %d holds", n)
+		}
+	}
+}
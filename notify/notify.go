@@ -0,0 +1,258 @@
+// Package notify streams balance updates from Postgres.
+// A trigger on the accounts table calls pg_notify whenever a committed transaction changes a balance,
+// and a Subscriber listens to those notifications and fans them out to per-account Go channels,
+// so that clients no longer have to poll GetAccount.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres notification channel of balance updates.
+const Channel = "account_balance"
+
+const (
+	minReconnectInterval = 10 * time.Second
+	maxReconnectInterval = time.Minute
+	// pingInterval is how long the subscriber waits for a notification before checking the connection.
+	pingInterval = 90 * time.Second
+	// bufferSize is the number of updates a subscription holds before older ones are dropped.
+	bufferSize = 16
+)
+
+// ErrClosed is returned when subscribing to a closed Subscriber.
+var ErrClosed = errors.New("subscriber closed")
+
+// BalanceUpdate is the payload of a notification: the balances of an account after a committed change.
+type BalanceUpdate struct {
+	AccountID        int64 `json:"account_id"`
+	Balance          int64 `json:"balance"`
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+// listener is the part of *pq.Listener the Subscriber uses.
+type listener interface {
+	NotificationChannel() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+// Subscriber fans balance updates out to the subscriptions of each account.
+// The underlying connection is re-established by pq.Listener after a failure. Notifications sent
+// while it was down are lost, so the subscriber then reads the current balances of every subscribed account
+// from the store, if it has one, and sends them as updates.
+type Subscriber struct {
+	listener listener
+	store    db.Querier
+
+	mu            sync.Mutex
+	subscriptions map[int64]map[chan BalanceUpdate]struct{}
+	closed        bool
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSubscriber connects to the database at dsn and listens to balance updates.
+// It keeps trying to connect until ctx is done. store is used to catch up after a reconnection and may be nil.
+func NewSubscriber(ctx context.Context, dsn string, store db.Querier) (*Subscriber, error) {
+	l := pq.NewListener(dsn, minReconnectInterval, maxReconnectInterval, logEvent)
+
+	// Listen only returns once the first connection is up
+	listening := make(chan error, 1)
+	go func() {
+		listening <- l.Listen(Channel)
+	}()
+
+	select {
+	case err := <-listening:
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+	case <-ctx.Done():
+		l.Close()
+		return nil, ctx.Err()
+	}
+	return newSubscriber(l, store), nil
+}
+
+func newSubscriber(l listener, store db.Querier) *Subscriber {
+	subscriber := &Subscriber{
+		listener:      l,
+		store:         store,
+		subscriptions: make(map[int64]map[chan BalanceUpdate]struct{}),
+		done:          make(chan struct{}),
+	}
+	subscriber.wg.Add(1)
+	go subscriber.run()
+	return subscriber
+}
+
+func logEvent(event pq.ListenerEventType, err error) {
+	if err != nil {
+		log.Printf("balance listener event %d: %v", event, err)
+	}
+}
+
+// Subscribe returns a channel receiving the balance updates of an account, and a function ending the subscription.
+// Only the latest updates are kept for a slow reader: when the channel is full, the oldest update is dropped.
+// The channel is closed when the subscription ends or the subscriber is closed.
+func (subscriber *Subscriber) Subscribe(accountID int64) (<-chan BalanceUpdate, func(), error) {
+	subscriber.mu.Lock()
+	defer subscriber.mu.Unlock()
+
+	if subscriber.closed {
+		return nil, nil, ErrClosed
+	}
+
+	ch := make(chan BalanceUpdate, bufferSize)
+	if subscriber.subscriptions[accountID] == nil {
+		subscriber.subscriptions[accountID] = make(map[chan BalanceUpdate]struct{})
+	}
+	subscriber.subscriptions[accountID][ch] = struct{}{}
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() { subscriber.unsubscribe(accountID, ch) })
+	}
+	return ch, unsubscribe, nil
+}
+
+func (subscriber *Subscriber) unsubscribe(accountID int64, ch chan BalanceUpdate) {
+	subscriber.mu.Lock()
+	defer subscriber.mu.Unlock()
+
+	if _, ok := subscriber.subscriptions[accountID][ch]; !ok {
+		// already closed by Close
+		return
+	}
+	delete(subscriber.subscriptions[accountID], ch)
+	if len(subscriber.subscriptions[accountID]) == 0 {
+		delete(subscriber.subscriptions, accountID)
+	}
+	close(ch)
+}
+
+// Close stops listening, closes the connection and every subscription channel.
+func (subscriber *Subscriber) Close() error {
+	subscriber.mu.Lock()
+	if subscriber.closed {
+		subscriber.mu.Unlock()
+		return nil
+	}
+	subscriber.closed = true
+	subscriber.mu.Unlock()
+
+	close(subscriber.done)
+	err := subscriber.listener.Close()
+	subscriber.wg.Wait()
+
+	subscriber.mu.Lock()
+	defer subscriber.mu.Unlock()
+	for accountID, channels := range subscriber.subscriptions {
+		for ch := range channels {
+			close(ch)
+		}
+		delete(subscriber.subscriptions, accountID)
+	}
+	return err
+}
+
+func (subscriber *Subscriber) run() {
+	defer subscriber.wg.Done()
+
+	notifications := subscriber.listener.NotificationChannel()
+	for {
+		select {
+		case <-subscriber.done:
+			return
+		case notification, ok := <-notifications:
+			if !ok {
+				return
+			}
+			// pq sends nil after re-establishing the connection
+			if notification == nil {
+				subscriber.resync()
+				continue
+			}
+			subscriber.handle(notification)
+		case <-time.After(pingInterval):
+			go subscriber.listener.Ping()
+		}
+	}
+}
+
+func (subscriber *Subscriber) handle(notification *pq.Notification) {
+	if notification.Channel != Channel {
+		return
+	}
+
+	var update BalanceUpdate
+	if err := json.Unmarshal([]byte(notification.Extra), &update); err != nil {
+		log.Printf("cannot parse balance update %q: %v", notification.Extra, err)
+		return
+	}
+	subscriber.publish(update)
+}
+
+// resync sends the current balances of every subscribed account.
+func (subscriber *Subscriber) resync() {
+	if subscriber.store == nil {
+		return
+	}
+
+	subscriber.mu.Lock()
+	accountIDs := make([]int64, 0, len(subscriber.subscriptions))
+	for accountID := range subscriber.subscriptions {
+		accountIDs = append(accountIDs, accountID)
+	}
+	subscriber.mu.Unlock()
+
+	for _, accountID := range accountIDs {
+		account, err := subscriber.store.GetAccount(context.Background(), accountID)
+		if err != nil {
+			log.Printf("cannot resync balance of account %d: %v", accountID, err)
+			continue
+		}
+		subscriber.publish(BalanceUpdate{
+			AccountID:        account.ID,
+			Balance:          account.Balance,
+			AvailableBalance: account.AvailableBalance,
+		})
+	}
+}
+
+func (subscriber *Subscriber) publish(update BalanceUpdate) {
+	subscriber.mu.Lock()
+	defer subscriber.mu.Unlock()
+
+	for ch := range subscriber.subscriptions[update.AccountID] {
+		send(ch, update)
+	}
+}
+
+// send delivers update without blocking, dropping the oldest update of a full channel.
+// Only the subscriber writes to ch, under its lock, so a slot freed here stays free.
+func send(ch chan BalanceUpdate, update BalanceUpdate) {
+	select {
+	case ch <- update:
+		return
+	default:
+	}
+
+	select {
+	case <-ch:
+	default:
+	}
+	ch <- update
+}
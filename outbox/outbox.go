@@ -0,0 +1,110 @@
+// Package outbox publishes the ledger events that the store records in its outbox table
+// in the same transaction as the change they describe.
+// A Relay polls the outbox and hands every event to a Publisher at least once, in the order they were recorded.
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "simplebank/db/sqlc"
+)
+
+const (
+	// DefaultBatchSize is the number of events locked and delivered per transaction when Options.BatchSize is zero.
+	DefaultBatchSize = 100
+	// DefaultPollInterval is how long the relay waits for new events when Options.PollInterval is zero.
+	DefaultPollInterval = time.Second
+)
+
+// Publisher delivers events to downstream services, e.g. through a message broker.
+type Publisher interface {
+	// Publish delivers event. An event may be published more than once,
+	// so consumers should ignore the events whose ID they already processed.
+	Publish(ctx context.Context, event db.OutboxEvent) error
+}
+
+// PublisherFunc adapts a function to the Publisher interface.
+type PublisherFunc func(ctx context.Context, event db.OutboxEvent) error
+
+func (f PublisherFunc) Publish(ctx context.Context, event db.OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// LogPublisher writes every event to Logger, or to the standard logger when Logger is nil.
+// It stands in for a real publisher in development.
+type LogPublisher struct {
+	Logger *log.Logger
+}
+
+func (publisher LogPublisher) Publish(ctx context.Context, event db.OutboxEvent) error {
+	logger := publisher.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf("event %d %s %d: %s", event.ID, event.EventType, event.AggregateID, event.Payload)
+	return nil
+}
+
+type Options struct {
+	// BatchSize is the number of events locked and delivered per transaction.
+	BatchSize int32
+	// PollInterval is how long Run waits before polling again once the outbox is empty or delivery failed.
+	PollInterval time.Duration
+}
+
+// Relay moves events from the outbox of a store to a publisher.
+// Several relays can run against the same database: each event is locked by one of them at a time.
+type Relay struct {
+	store     db.Store
+	publisher Publisher
+	options   Options
+}
+
+// NewRelay creates a relay publishing the events of store to publisher.
+func NewRelay(store db.Store, publisher Publisher, options Options) *Relay {
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	if options.PollInterval <= 0 {
+		options.PollInterval = DefaultPollInterval
+	}
+	return &Relay{store: store, publisher: publisher, options: options}
+}
+
+// Drain publishes batches of events until the outbox is empty, and returns the number of events published.
+// It stops at the first event the publisher fails to publish, which is retried by the next call.
+func (relay *Relay) Drain(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := relay.store.DeliverOutboxEvents(ctx, relay.options.BatchSize, relay.publisher.Publish)
+		total += n
+		if err != nil || n < int(relay.options.BatchSize) {
+			return total, err
+		}
+	}
+}
+
+// Run drains the outbox every poll interval until ctx is done, and returns the error of ctx.
+// Publishing errors are logged and retried at the next poll.
+func (relay *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(relay.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := relay.Drain(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Println("cannot publish outbox events:", err)
+		}
+		if n > 0 {
+			log.Printf("published %d outbox events", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a Publisher that remembers what it published and fails while err is set.
+type recorder struct {
+	published []db.OutboxEvent
+	err       error
+}
+
+func (r *recorder) Publish(ctx context.Context, event db.OutboxEvent) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.published = append(r.published, event)
+	return nil
+}
+
+func newStoreWithEvents(t *testing.T, n int) db.Store {
+	ctx := context.Background()
+	store := db.NewMemoryStore()
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: "secret",
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	})
+	require.NoError(t, err)
+	account, err := store.CreateAccount(ctx, db.CreateAccountParams{Owner: user.Username, Currency: utils.USD})
+	require.NoError(t, err)
+
+	for i := 0; i < n; i++ {
+		_, err := store.DepositTx(ctx, db.DepositTxParams{AccountID: account.ID, Amount: 10})
+		require.NoError(t, err)
+	}
+	return store
+}
+
+func TestRelayDrain(t *testing.T) {
+	store := newStoreWithEvents(t, 5)
+	publisher := &recorder{}
+	relay := NewRelay(store, publisher, Options{BatchSize: 2})
+
+	n, err := relay.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.Len(t, publisher.published, 5)
+	for i, event := range publisher.published {
+		require.Equal(t, db.EventTypeEntryPosted, event.EventType)
+		if i > 0 {
+			require.Less(t, publisher.published[i-1].ID, event.ID)
+		}
+	}
+
+	n, err = relay.Drain(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestRelayDrainFailure(t *testing.T) {
+	store := newStoreWithEvents(t, 3)
+	errBroker := errors.New("broker unavailable")
+	publisher := &recorder{err: errBroker}
+	relay := NewRelay(store, publisher, Options{})
+
+	n, err := relay.Drain(context.Background())
+	require.ErrorIs(t, err, errBroker)
+	require.Zero(t, n)
+	require.Empty(t, publisher.published)
+
+	// nothing is lost: the events are published once the publisher recovers
+	publisher.err = nil
+	n, err = relay.Drain(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Len(t, publisher.published, 3)
+}
+
+func TestRelayRun(t *testing.T) {
+	store := newStoreWithEvents(t, 3)
+	published := make(chan db.OutboxEvent, 10)
+	publisher := PublisherFunc(func(ctx context.Context, event db.OutboxEvent) error {
+		published <- event
+		return nil
+	})
+	relay := NewRelay(store, publisher, Options{PollInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- relay.Run(ctx)
+	}()
+
+	for i := 0; i < 3; i++ {
+		<-published
+	}
+
+	// events recorded while the relay runs are published at the next poll
+	accounts, err := store.ListAccounts(context.Background(), db.ListAccountsParams{Limit: 1})
+	require.NoError(t, err)
+	_, err = store.DepositTx(context.Background(), db.DepositTxParams{AccountID: accounts[0].ID, Amount: 10})
+	require.NoError(t, err)
+	select {
+	case event := <-published:
+		require.Equal(t, db.EventTypeEntryPosted, event.EventType)
+	case <-time.After(time.Second):
+		t.Fatal("event was not published")
+	}
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,188 @@
+// Package reconcile checks the invariants of the ledger:
+// the balance of every account equals the sum of its entries,
+// every posted transfer has exactly one debit and one credit entry linked to it and any other transfer has none,
+// and every entry of type transfer is a leg of the transfer it is linked to.
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "simplebank/db/sqlc"
+)
+
+// DefaultBatchSize is the number of rows read per query when Options.BatchSize is zero.
+const DefaultBatchSize = 1000
+
+type Options struct {
+	// BatchSize is the number of accounts, transfers or entries read per query.
+	BatchSize int32
+}
+
+// BalanceDrift is an account whose balance differs from the sum of its entries.
+type BalanceDrift struct {
+	AccountID    int64 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	Drift        int64 `json:"drift"`
+}
+
+// OrphanEntry is a transfer entry that is not a leg of the transfer it is linked to, if any.
+type OrphanEntry struct {
+	EntryID    int64     `json:"entry_id"`
+	AccountID  int64     `json:"account_id"`
+	Amount     int64     `json:"amount"`
+	CreatedAt  time.Time `json:"created_at"`
+	TransferID int64     `json:"transfer_id,omitempty"`
+}
+
+// UnmatchedTransfer is a posted transfer without exactly one debit and one credit entry,
+// or a pending or failed transfer with entries.
+type UnmatchedTransfer struct {
+	TransferID    int64             `json:"transfer_id"`
+	FromAccountID int64             `json:"from_account_id"`
+	ToAccountID   int64             `json:"to_account_id"`
+	Amount        int64             `json:"amount"`
+	Status        db.TransferStatus `json:"status"`
+	DebitEntries  int64             `json:"debit_entries"`
+	CreditEntries int64             `json:"credit_entries"`
+}
+
+type Report struct {
+	StartedAt          time.Time           `json:"started_at"`
+	FinishedAt         time.Time           `json:"finished_at"`
+	AccountsChecked    int64               `json:"accounts_checked"`
+	TransfersChecked   int64               `json:"transfers_checked"`
+	EntriesChecked     int64               `json:"entries_checked"`
+	BalanceDrifts      []BalanceDrift      `json:"balance_drifts"`
+	UnmatchedTransfers []UnmatchedTransfer `json:"unmatched_transfers"`
+	OrphanEntries      []OrphanEntry       `json:"orphan_entries"`
+}
+
+// OK reports whether no discrepancy was found.
+func (report Report) OK() bool {
+	return len(report.BalanceDrifts) == 0 && len(report.UnmatchedTransfers) == 0 && len(report.OrphanEntries) == 0
+}
+
+// Run scans the whole ledger in batches of opts.BatchSize rows and reports every discrepancy it finds.
+// Each batch is a single query, and a transfer is committed together with its entries and balance updates,
+// so writes that happen during the scan do not show up as discrepancies.
+func Run(ctx context.Context, q db.Querier, opts Options) (Report, error) {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.BatchSize < 0 {
+		return Report{}, fmt.Errorf("invalid batch size %d", opts.BatchSize)
+	}
+
+	report := Report{
+		StartedAt:          time.Now(),
+		BalanceDrifts:      []BalanceDrift{},
+		UnmatchedTransfers: []UnmatchedTransfer{},
+		OrphanEntries:      []OrphanEntry{},
+	}
+
+	if err := checkBalances(ctx, q, opts.BatchSize, &report); err != nil {
+		return report, fmt.Errorf("check balances: %w", err)
+	}
+	if err := checkTransfers(ctx, q, opts.BatchSize, &report); err != nil {
+		return report, fmt.Errorf("check transfers: %w", err)
+	}
+	if err := checkEntries(ctx, q, opts.BatchSize, &report); err != nil {
+		return report, fmt.Errorf("check entries: %w", err)
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+func checkBalances(ctx context.Context, q db.Querier, batchSize int32, report *Report) error {
+	var afterID int64
+	for {
+		rows, err := q.ListAccountEntryTotals(ctx, db.ListAccountEntryTotalsParams{AfterID: afterID, BatchSize: batchSize})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if row.Balance != row.EntriesTotal {
+				report.BalanceDrifts = append(report.BalanceDrifts, BalanceDrift{
+					AccountID:    row.ID,
+					Balance:      row.Balance,
+					EntriesTotal: row.EntriesTotal,
+					Drift:        row.Balance - row.EntriesTotal,
+				})
+			}
+		}
+		report.AccountsChecked += int64(len(rows))
+
+		if int32(len(rows)) < batchSize {
+			return nil
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
+
+func checkTransfers(ctx context.Context, q db.Querier, batchSize int32, report *Report) error {
+	var afterID int64
+	for {
+		rows, err := q.ListTransferEntryMatches(ctx, db.ListTransferEntryMatchesParams{AfterID: afterID, BatchSize: batchSize})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			// only a posted transfer has moved money
+			var legs int64
+			if row.Status == db.TransferStatusPosted {
+				legs = 1
+			}
+			if row.DebitEntries != legs || row.CreditEntries != legs {
+				report.UnmatchedTransfers = append(report.UnmatchedTransfers, UnmatchedTransfer{
+					TransferID:    row.ID,
+					FromAccountID: row.FromAccountID,
+					ToAccountID:   row.ToAccountID,
+					Amount:        row.Amount,
+					Status:        row.Status,
+					DebitEntries:  row.DebitEntries,
+					CreditEntries: row.CreditEntries,
+				})
+			}
+		}
+		report.TransfersChecked += int64(len(rows))
+
+		if int32(len(rows)) < batchSize {
+			return nil
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
+
+func checkEntries(ctx context.Context, q db.Querier, batchSize int32, report *Report) error {
+	var afterID int64
+	for {
+		rows, err := q.ListEntryTransferMatches(ctx, db.ListEntryTransferMatchesParams{AfterID: afterID, BatchSize: batchSize})
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			if row.Type == db.EntryTypeTransfer && row.MatchingTransfers == 0 {
+				report.OrphanEntries = append(report.OrphanEntries, OrphanEntry{
+					EntryID:    row.ID,
+					AccountID:  row.AccountID,
+					Amount:     row.Amount,
+					CreatedAt:  row.CreatedAt,
+					TransferID: row.TransferID.Int64,
+				})
+			}
+		}
+		report.EntriesChecked += int64(len(rows))
+
+		if int32(len(rows)) < batchSize {
+			return nil
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
@@ -0,0 +1,138 @@
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	db "simplebank/db/sqlc"
+	"simplebank/db/utils"
+	"simplebank/fx"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createAccount(t *testing.T, store db.Store) db.Account {
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: "secret",
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	account, err := store.CreateAccount(context.Background(), db.CreateAccountParams{
+		Owner:    user.Username,
+		Currency: utils.USD,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+// fund credits an account with a deposit.
+func fund(t *testing.T, store db.Store, accountID int64, amount int64) {
+	_, err := store.DepositTx(context.Background(), db.DepositTxParams{AccountID: accountID, Amount: db.Amount(amount)})
+	require.NoError(t, err)
+}
+
+func TestRunConsistentLedger(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryStore()
+
+	report, err := Run(ctx, store, Options{})
+	require.NoError(t, err)
+	require.True(t, report.OK())
+	require.Zero(t, report.AccountsChecked)
+
+	account1 := createAccount(t, store)
+	account2 := createAccount(t, store)
+	fund(t, store, account2.ID, 100)
+	for i := 0; i < 5; i++ {
+		_, err := store.TransferTx(ctx, db.TransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10})
+		require.NoError(t, err)
+	}
+
+	// pending and failed transfers have no entries yet
+	pending, err := store.CreatePendingTransferTx(ctx, db.CreatePendingTransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10})
+	require.NoError(t, err)
+	_, err = store.CreatePendingTransferTx(ctx, db.CreatePendingTransferTxParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10})
+	require.NoError(t, err)
+	_, err = store.VoidTransferTx(ctx, pending.Transfer.ID)
+	require.NoError(t, err)
+
+	// batches smaller than the tables make Run page through them
+	report, err = Run(ctx, store, Options{BatchSize: 2})
+	require.NoError(t, err)
+	require.True(t, report.OK())
+	require.Equal(t, int64(2), report.AccountsChecked)
+	require.Equal(t, int64(7), report.TransfersChecked)
+	require.Equal(t, int64(11), report.EntriesChecked)
+	require.False(t, report.FinishedAt.Before(report.StartedAt))
+}
+
+func TestRunDiscrepancies(t *testing.T) {
+	ctx := context.Background()
+	store := db.NewMemoryStore()
+
+	account1 := createAccount(t, store)
+	account2 := createAccount(t, store)
+	fund(t, store, account1.ID, 100)
+
+	_, err := store.TransferTx(ctx, db.TransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 30})
+	require.NoError(t, err)
+
+	// a transfer entry that is not linked to a transfer
+	orphan, err := store.CreateEntry(ctx, db.CreateEntryParams{AccountID: account1.ID, Amount: 0, Type: db.EntryTypeTransfer})
+	require.NoError(t, err)
+
+	// a balance update without an entry
+	_, err = store.AddAccountBalance(ctx, db.AddAccountBalanceParams{ID: account2.ID, Amount: 5})
+	require.NoError(t, err)
+
+	// a transfer without entries
+	transfer, err := store.CreateTransfer(ctx, db.CreateTransferParams{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 7, Status: db.TransferStatusPosted})
+	require.NoError(t, err)
+
+	report, err := Run(ctx, store, Options{BatchSize: 1})
+	require.NoError(t, err)
+	require.False(t, report.OK())
+
+	require.Equal(t, []BalanceDrift{
+		{AccountID: account2.ID, Balance: 35, EntriesTotal: 30, Drift: 5},
+	}, report.BalanceDrifts)
+
+	require.Equal(t, []UnmatchedTransfer{
+		{TransferID: transfer.ID, FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 7, Status: db.TransferStatusPosted},
+	}, report.UnmatchedTransfers)
+
+	require.Len(t, report.OrphanEntries, 1)
+	require.Equal(t, orphan.ID, report.OrphanEntries[0].EntryID)
+	require.Zero(t, report.OrphanEntries[0].TransferID)
+
+	require.Equal(t, int64(2), report.TransfersChecked)
+}
+
+func TestRunExchangeTransfers(t *testing.T) {
+	ctx := context.Background()
+	provider, err := fx.NewStaticProvider(map[string]string{"USD/EUR": "0.9"}, 0)
+	require.NoError(t, err)
+	store := db.NewMemoryStoreWithRateProvider(provider)
+
+	account1 := createAccount(t, store)
+	account2, err := store.CreateAccount(ctx, db.CreateAccountParams{Owner: account1.Owner, Currency: utils.EUR})
+	require.NoError(t, err)
+	fund(t, store, account1.ID, 100)
+
+	_, err = store.ExchangeTransferTx(ctx, db.ExchangeTransferTxParams{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 100})
+	require.NoError(t, err)
+
+	// the credit leg matches the converted amount
+	report, err := Run(ctx, store, Options{})
+	require.NoError(t, err)
+	require.True(t, report.OK())
+	require.Equal(t, int64(1), report.TransfersChecked)
+}
+
+func TestRunInvalidBatchSize(t *testing.T) {
+	_, err := Run(context.Background(), db.NewMemoryStore(), Options{BatchSize: -1})
+	require.Error(t, err)
+}
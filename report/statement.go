@@ -0,0 +1,100 @@
+// Package report renders account statements for people and spreadsheets.
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	db "simplebank/db/sqlc"
+)
+
+// csvHeader lists the columns written by WriteCSV.
+var csvHeader = []string{"date", "entry_id", "description", "amount", "balance", "transfer_id", "counterparty_account_id"}
+
+// WriteCSV writes the statement as CSV: a header, an opening balance row, one row per entry and a closing balance row.
+// Amounts are in the smallest unit of the account currency.
+func WriteCSV(w io.Writer, statement db.AccountStatement) error {
+	cw := csv.NewWriter(w)
+
+	records := [][]string{
+		csvHeader,
+		{formatTime(statement.From), "", "opening balance", "", formatInt(statement.OpeningBalance), "", ""},
+	}
+	for _, line := range statement.Lines {
+		records = append(records, []string{
+			formatTime(line.Entry.CreatedAt),
+			formatInt(line.Entry.ID),
+			Description(line),
+			formatInt(line.Entry.Amount),
+			formatInt(line.Balance),
+			formatID(line.TransferID),
+			formatID(line.CounterpartyAccountID),
+		})
+	}
+	records = append(records,
+		[]string{formatTime(statement.To), "", "closing balance", "", formatInt(statement.ClosingBalance), "", ""})
+
+	return cw.WriteAll(records)
+}
+
+// WriteText writes the statement as an aligned plain-text table, meant to be read or printed as is.
+func WriteText(w io.Writer, statement db.AccountStatement) error {
+	account := statement.Account
+	_, err := fmt.Fprintf(w, "Statement for account %d (%s, %s)\nPeriod: %s to %s\n\n",
+		account.ID, account.Owner, account.Currency, formatTime(statement.From), formatTime(statement.To))
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "Date\tEntry\tDescription\tAmount\tBalance\t\n")
+	fmt.Fprintf(tw, "%s\t\t%s\t\t%d\t\n", formatTime(statement.From), "Opening balance", statement.OpeningBalance)
+	for _, line := range statement.Lines {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%d\t%d\t\n",
+			formatTime(line.Entry.CreatedAt), line.Entry.ID, Description(line), line.Entry.Amount, line.Balance)
+	}
+	fmt.Fprintf(tw, "%s\t\t%s\t\t%d\t\n", formatTime(statement.To), "Closing balance", statement.ClosingBalance)
+	return tw.Flush()
+}
+
+// WriteJSON writes the statement as indented JSON.
+func WriteJSON(w io.Writer, statement db.AccountStatement) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(statement)
+}
+
+// Description says in a few words what a statement line is.
+func Description(line db.StatementLine) string {
+	switch {
+	case line.TransferID == 0 && line.Entry.Type != "":
+		return string(line.Entry.Type)
+	case line.TransferID == 0:
+		return "entry"
+	case line.Entry.Amount < 0:
+		return fmt.Sprintf("transfer to account %d", line.CounterpartyAccountID)
+	default:
+		return fmt.Sprintf("transfer from account %d", line.CounterpartyAccountID)
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatInt(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// formatID leaves missing IDs empty instead of writing 0.
+func formatID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return formatInt(id)
+}
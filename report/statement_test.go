@@ -0,0 +1,90 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	db "simplebank/db/sqlc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement() db.AccountStatement {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	return db.AccountStatement{
+		Account:        db.Account{ID: 1, Owner: "alice", Balance: 935, Currency: "USD"},
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 1000,
+		Lines: []db.StatementLine{
+			{
+				Entry:                 db.Entry{ID: 10, AccountID: 1, Amount: -100, CreatedAt: from.Add(time.Hour)},
+				Balance:               900,
+				TransferID:            5,
+				CounterpartyAccountID: 2,
+			},
+			{
+				Entry:                 db.Entry{ID: 12, AccountID: 1, Amount: 30, CreatedAt: from.Add(2 * time.Hour)},
+				Balance:               930,
+				TransferID:            6,
+				CounterpartyAccountID: 3,
+			},
+			{
+				Entry:   db.Entry{ID: 13, AccountID: 1, Amount: 5, CreatedAt: from.Add(3 * time.Hour), Type: db.EntryTypeDeposit},
+				Balance: 935,
+			},
+		},
+		ClosingBalance: 935,
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, testStatement()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"2024-03-01T00:00:00Z", "", "opening balance", "", "1000", "", ""},
+		{"2024-03-01T01:00:00Z", "10", "transfer to account 2", "-100", "900", "5", "2"},
+		{"2024-03-01T02:00:00Z", "12", "transfer from account 3", "30", "930", "6", "3"},
+		{"2024-03-01T03:00:00Z", "13", "deposit", "5", "935", "", ""},
+		{"2024-04-01T00:00:00Z", "", "closing balance", "", "935", "", ""},
+	}, records)
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, testStatement()))
+
+	text := buf.String()
+	require.True(t, strings.HasPrefix(text, "Statement for account 1 (alice, USD)\n"))
+	require.Contains(t, text, "Period: 2024-03-01T00:00:00Z to 2024-04-01T00:00:00Z")
+
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	require.Len(t, lines, 9)
+	require.Contains(t, lines[4], "Opening balance")
+	require.Contains(t, lines[5], "transfer to account 2")
+	require.True(t, strings.HasSuffix(lines[8], " 935"))
+
+	// columns are aligned
+	for _, line := range lines[4:] {
+		require.Equal(t, len(lines[3]), len(line))
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	statement := testStatement()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, statement))
+
+	var got db.AccountStatement
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Equal(t, statement, got)
+}
@@ -0,0 +1,70 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"simplebank/db/utils"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasetoMaker(t *testing.T) {
+	maker, err := NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
+	username := utils.RandomOwner()
+	duration := time.Minute
+
+	issuedAt := time.Now()
+	expiredAt := issuedAt.Add(duration)
+
+	token, payload, err := maker.CreateToken(username, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoToken(t *testing.T) {
+	maker, err := NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
+	token, payload, err := maker.CreateToken(utils.RandomOwner(), -time.Minute)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+	require.NotEmpty(t, payload)
+
+	payload, err = maker.VerifyToken(token)
+	require.Error(t, err)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestInvalidPasetoTokenOtherKey(t *testing.T) {
+	maker1, err := NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+	maker2, err := NewPasetoMaker(utils.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker1.CreateToken(utils.RandomOwner(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker2.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoMakerInvalidKeySize(t *testing.T) {
+	maker, err := NewPasetoMaker(utils.RandomString(31))
+	require.Error(t, err)
+	require.Nil(t, maker)
+}